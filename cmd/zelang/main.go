@@ -0,0 +1,101 @@
+// Command zelang is the zelang CLI.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gunesh/zelang/pkg/devserver"
+	"github.com/gunesh/zelang/pkg/lexer"
+	"github.com/gunesh/zelang/pkg/parser"
+	"github.com/gunesh/zelang/pkg/printer"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "serve":
+		if err := runServe(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "zelang serve:", err)
+			os.Exit(1)
+		}
+	case "fmt":
+		if err := runFmt(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "zelang fmt:", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: zelang serve [flags]")
+	fmt.Fprintln(os.Stderr, "       zelang fmt [file]")
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	sourceDir := fs.String("dir", ".", "directory of .zl sources to watch")
+	addr := fs.String("addr", ":4000", "address for the dev-server proxy to listen on")
+	backendAddr := fs.String("backend-addr", "127.0.0.1:8080", "address the generated app's own HTTP server binds to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	srv, err := devserver.New(devserver.Config{
+		SourceDir:   *sourceDir,
+		Addr:        *addr,
+		BackendAddr: *backendAddr,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return srv.Run(ctx)
+}
+
+// runFmt reads a .zl source file (or stdin, given "-" or no argument),
+// reformats it to canonical zelang source, and writes the result to stdout.
+func runFmt(args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filename := "-"
+	if fs.NArg() > 0 {
+		filename = fs.Arg(0)
+	}
+
+	var src []byte
+	var err error
+	var l *lexer.Lexer
+	if filename == "-" {
+		src, err = io.ReadAll(os.Stdin)
+		l = lexer.New(string(src))
+	} else {
+		src, err = os.ReadFile(filename)
+		l = lexer.NewFile(filename, string(src))
+	}
+	if err != nil {
+		return err
+	}
+
+	program := parser.NewWithComments(l).ParseProgram()
+	_, err = fmt.Fprint(os.Stdout, printer.Fprint(program))
+	return err
+}
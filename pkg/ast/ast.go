@@ -1,13 +1,28 @@
 package ast
 
-// Node represents any node in the AST
+import "github.com/gunesh/zelang/pkg/token"
+
+// Node represents any node in the AST. Pos and End delimit the node's
+// source span as a half-open byte range starting at Pos and ending just
+// before End, so tools (diagnostics, a future language server,
+// source-accurate codegen maps) can point at exactly the bytes a node came
+// from; resolve them to a file:line:col via the *token.FileSet the
+// parser's Lexer was built with.
 type Node interface {
 	TokenLiteral() string
+	Pos() token.Pos
+	End() token.Pos
 }
 
 // Program is the root node
 type Program struct {
 	Statements []Node
+
+	// Comments holds every comment group the parser scanned, in source
+	// order, regardless of whether it was also attached to a declaration
+	// as a Doc or LineComment. Populated only when comment scanning was
+	// enabled (see parser.NewWithComments).
+	Comments []*CommentGroup
 }
 
 func (p *Program) TokenLiteral() string {
@@ -17,129 +32,232 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() token.Pos {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.NoPos
+}
+
+func (p *Program) End() token.Pos {
+	if n := len(p.Statements); n > 0 {
+		return p.Statements[n-1].End()
+	}
+	return token.NoPos
+}
+
 // Decorator represents @decorator annotations
 type Decorator struct {
-	Name   string
-	Args   []string
-	KVArgs map[string]string // For key: value arguments
+	Name     string
+	Args     []string
+	KVArgs   map[string]string // For key: value arguments
+	StartPos token.Pos
+	EndPos   token.Pos
 }
 
 func (d *Decorator) TokenLiteral() string { return "@" + d.Name }
+func (d *Decorator) Pos() token.Pos       { return d.StartPos }
+func (d *Decorator) End() token.Pos       { return d.EndPos }
 
 // StructDecl represents a struct definition
 type StructDecl struct {
 	Name       string
 	Decorators []*Decorator
 	Fields     []*FieldDecl
+	Doc        *CommentGroup // comment immediately preceding the struct, on its own line
+	LineComment *CommentGroup // comment trailing the struct's closing brace, on the same line
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (s *StructDecl) TokenLiteral() string { return "struct" }
+func (s *StructDecl) Pos() token.Pos       { return s.StartPos }
+func (s *StructDecl) End() token.Pos       { return s.EndPos }
 
 // FieldDecl represents a field in a struct
 type FieldDecl struct {
-	Name       string
-	Type       string
-	IsArray    bool
-	Decorators []*Decorator
+	Name        string
+	Type        string
+	IsArray     bool
+	Decorators  []*Decorator
+	Doc         *CommentGroup // comment immediately preceding the field, on its own line
+	LineComment *CommentGroup // comment trailing the field's ';', on the same line
+	StartPos    token.Pos
+	EndPos      token.Pos
 }
 
 func (f *FieldDecl) TokenLiteral() string { return f.Name }
+func (f *FieldDecl) Pos() token.Pos       { return f.StartPos }
+func (f *FieldDecl) End() token.Pos       { return f.EndPos }
 
 // PageDecl represents a Page UI component
 type PageDecl struct {
-	Name       string
-	Route      string
-	Decorators []*Decorator
-	Properties map[string]string
-	Body       []Node
+	Name        string
+	Route       string
+	Decorators  []*Decorator
+	Properties  map[string]string
+	Body        []Node
+	Doc         *CommentGroup
+	LineComment *CommentGroup
+	StartPos    token.Pos
+	EndPos      token.Pos
 }
 
 func (p *PageDecl) TokenLiteral() string { return "Page" }
+func (p *PageDecl) Pos() token.Pos       { return p.StartPos }
+func (p *PageDecl) End() token.Pos       { return p.EndPos }
 
 // SectionDecl represents a Section UI component
 type SectionDecl struct {
 	Properties map[string]string
 	Body       []Node
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (s *SectionDecl) TokenLiteral() string { return "Section" }
+func (s *SectionDecl) Pos() token.Pos       { return s.StartPos }
+func (s *SectionDecl) End() token.Pos       { return s.EndPos }
 
 // RowDecl represents a Row UI component
 type RowDecl struct {
 	Properties map[string]string
 	Body       []Node
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (r *RowDecl) TokenLiteral() string { return "Row" }
+func (r *RowDecl) Pos() token.Pos       { return r.StartPos }
+func (r *RowDecl) End() token.Pos       { return r.EndPos }
 
 // ColumnDecl represents a Column UI component
 type ColumnDecl struct {
 	Properties map[string]string
 	Body       []Node
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (c *ColumnDecl) TokenLiteral() string { return "Column" }
+func (c *ColumnDecl) Pos() token.Pos       { return c.StartPos }
+func (c *ColumnDecl) End() token.Pos       { return c.EndPos }
 
 // DataListDecl represents a DataList UI component
 type DataListDecl struct {
 	Properties map[string]interface{}
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (d *DataListDecl) TokenLiteral() string { return "DataList" }
+func (d *DataListDecl) Pos() token.Pos       { return d.StartPos }
+func (d *DataListDecl) End() token.Pos       { return d.EndPos }
 
 // FormDecl represents a Form UI component
 type FormDecl struct {
 	Properties map[string]string
 	Body       []Node
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (f *FormDecl) TokenLiteral() string { return "Form" }
+func (f *FormDecl) Pos() token.Pos       { return f.StartPos }
+func (f *FormDecl) End() token.Pos       { return f.EndPos }
 
 // InputDecl represents an Input UI component
 type InputDecl struct {
 	Properties map[string]string
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (i *InputDecl) TokenLiteral() string { return "Input" }
+func (i *InputDecl) Pos() token.Pos       { return i.StartPos }
+func (i *InputDecl) End() token.Pos       { return i.EndPos }
 
 // ButtonDecl represents a Button UI component
 type ButtonDecl struct {
 	Properties map[string]string
+	StartPos   token.Pos
+	EndPos     token.Pos
 }
 
 func (b *ButtonDecl) TokenLiteral() string { return "Button" }
+func (b *ButtonDecl) Pos() token.Pos       { return b.StartPos }
+func (b *ButtonDecl) End() token.Pos       { return b.EndPos }
 
 // HandlerDecl represents a handler function
 type HandlerDecl struct {
-	Path       string
-	Method     string
-	Name       string
-	Parameters []*Parameter
-	Body       []Node
-	Decorators []*Decorator
+	Path        string
+	Method      string
+	Name        string
+	Parameters  []*Parameter
+	Body        []Node
+	Decorators  []*Decorator
+	Doc         *CommentGroup
+	LineComment *CommentGroup
+	StartPos    token.Pos
+	EndPos      token.Pos
 }
 
 func (h *HandlerDecl) TokenLiteral() string { return "handler" }
+func (h *HandlerDecl) Pos() token.Pos       { return h.StartPos }
+func (h *HandlerDecl) End() token.Pos       { return h.EndPos }
 
 // Parameter represents a function parameter
 type Parameter struct {
-	Name string
-	Type string
+	Name     string
+	Type     string
+	StartPos token.Pos
+	EndPos   token.Pos
 }
 
+func (p *Parameter) TokenLiteral() string { return p.Name }
+func (p *Parameter) Pos() token.Pos       { return p.StartPos }
+func (p *Parameter) End() token.Pos       { return p.EndPos }
+
 // FunctionDecl represents a function
 type FunctionDecl struct {
-	Name       string
-	ReturnType string
-	Parameters []*Parameter
-	Body       []Node
+	Name        string
+	ReturnType  string
+	Parameters  []*Parameter
+	Body        []Node
+	Doc         *CommentGroup
+	LineComment *CommentGroup
+	StartPos    token.Pos
+	EndPos      token.Pos
 }
 
 func (f *FunctionDecl) TokenLiteral() string { return f.Name }
+func (f *FunctionDecl) Pos() token.Pos       { return f.StartPos }
+func (f *FunctionDecl) End() token.Pos       { return f.EndPos }
 
 // MainDecl represents the main function
 type MainDecl struct {
-	Body []Node
+	Body     []Node
+	StartPos token.Pos
+	EndPos   token.Pos
 }
 
 func (m *MainDecl) TokenLiteral() string { return "main" }
+func (m *MainDecl) Pos() token.Pos       { return m.StartPos }
+func (m *MainDecl) End() token.Pos       { return m.EndPos }
+
+// HookDecl represents a `hook on <Struct> { event, event, ... }` block (row
+// events compiled to a sqlite3_update_hook dispatch) or a global
+// `hook { event, event, ... }` block (on_commit/on_rollback, compiled to
+// sqlite3_commit_hook/sqlite3_rollback_hook). StructName is "" for the
+// latter. Events holds only the event names; unlike FunctionDecl, a hook has
+// no body, and the parser captures none.
+type HookDecl struct {
+	StructName string
+	Events     []string
+	StartPos   token.Pos
+	EndPos     token.Pos
+}
+
+func (h *HookDecl) TokenLiteral() string { return "hook" }
+func (h *HookDecl) Pos() token.Pos       { return h.StartPos }
+func (h *HookDecl) End() token.Pos       { return h.EndPos }
@@ -0,0 +1,26 @@
+package ast
+
+import "github.com/gunesh/zelang/pkg/token"
+
+// Comment represents a single `//` or `/* */` comment, Text including its
+// comment markers, exactly as the lexer scanned it.
+type Comment struct {
+	Text     string
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (c *Comment) TokenLiteral() string { return c.Text }
+func (c *Comment) Pos() token.Pos       { return c.StartPos }
+func (c *Comment) End() token.Pos       { return c.EndPos }
+
+// CommentGroup represents a run of consecutive comments with no other
+// tokens between them - the unit that gets attached to a declaration as a
+// Doc or LineComment, mirroring go/ast.CommentGroup.
+type CommentGroup struct {
+	List []*Comment
+}
+
+func (g *CommentGroup) TokenLiteral() string { return g.List[0].Text }
+func (g *CommentGroup) Pos() token.Pos       { return g.List[0].Pos() }
+func (g *CommentGroup) End() token.Pos       { return g.List[len(g.List)-1].End() }
@@ -0,0 +1,139 @@
+package ast
+
+import "github.com/gunesh/zelang/pkg/token"
+
+// Expr is implemented by every expression node. The empty exprNode method
+// keeps arbitrary Nodes from satisfying Expr by accident, mirroring how
+// go/ast separates Expr from the general Node interface.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Ident is a bare identifier reference, e.g. a variable or function name.
+type Ident struct {
+	Name     string
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (i *Ident) exprNode()            {}
+func (i *Ident) TokenLiteral() string { return i.Name }
+func (i *Ident) Pos() token.Pos       { return i.StartPos }
+func (i *Ident) End() token.Pos       { return i.EndPos }
+
+// IntLit is an integer literal.
+type IntLit struct {
+	Value    int64
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (l *IntLit) exprNode()            {}
+func (l *IntLit) TokenLiteral() string { return "int" }
+func (l *IntLit) Pos() token.Pos       { return l.StartPos }
+func (l *IntLit) End() token.Pos       { return l.EndPos }
+
+// FloatLit is a floating-point literal.
+type FloatLit struct {
+	Value    float64
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (l *FloatLit) exprNode()            {}
+func (l *FloatLit) TokenLiteral() string { return "float" }
+func (l *FloatLit) Pos() token.Pos       { return l.StartPos }
+func (l *FloatLit) End() token.Pos       { return l.EndPos }
+
+// StringLit is a quoted string literal; Value holds the unescaped contents
+// (no surrounding quotes).
+type StringLit struct {
+	Value    string
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (l *StringLit) exprNode()            {}
+func (l *StringLit) TokenLiteral() string { return "string" }
+func (l *StringLit) Pos() token.Pos       { return l.StartPos }
+func (l *StringLit) End() token.Pos       { return l.EndPos }
+
+// BoolLit is a `true`/`false` literal.
+type BoolLit struct {
+	Value    bool
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (l *BoolLit) exprNode()            {}
+func (l *BoolLit) TokenLiteral() string { return "bool" }
+func (l *BoolLit) Pos() token.Pos       { return l.StartPos }
+func (l *BoolLit) End() token.Pos       { return l.EndPos }
+
+// BinaryExpr is a two-operand expression, e.g. `a + b` or (since ASSIGN is
+// just another precedence-table entry) `a = b`.
+type BinaryExpr struct {
+	Left     Expr
+	Op       string
+	Right    Expr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (b *BinaryExpr) exprNode()            {}
+func (b *BinaryExpr) TokenLiteral() string { return b.Op }
+func (b *BinaryExpr) Pos() token.Pos       { return b.StartPos }
+func (b *BinaryExpr) End() token.Pos       { return b.EndPos }
+
+// UnaryExpr is a single-operand prefix expression, e.g. `-a` or `!done`.
+type UnaryExpr struct {
+	Op       string
+	Operand  Expr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (u *UnaryExpr) exprNode()            {}
+func (u *UnaryExpr) TokenLiteral() string { return u.Op }
+func (u *UnaryExpr) Pos() token.Pos       { return u.StartPos }
+func (u *UnaryExpr) End() token.Pos       { return u.EndPos }
+
+// CallExpr is a function call, e.g. `sum(a, b)`.
+type CallExpr struct {
+	Callee   Expr
+	Args     []Expr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (c *CallExpr) exprNode()            {}
+func (c *CallExpr) TokenLiteral() string { return c.Callee.TokenLiteral() }
+func (c *CallExpr) Pos() token.Pos       { return c.StartPos }
+func (c *CallExpr) End() token.Pos       { return c.EndPos }
+
+// IndexExpr is a subscript expression, e.g. `items[0]`.
+type IndexExpr struct {
+	Target   Expr
+	Index    Expr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (x *IndexExpr) exprNode()            {}
+func (x *IndexExpr) TokenLiteral() string { return "[" }
+func (x *IndexExpr) Pos() token.Pos       { return x.StartPos }
+func (x *IndexExpr) End() token.Pos       { return x.EndPos }
+
+// SelectorExpr is a field/member access, e.g. `request.body`.
+type SelectorExpr struct {
+	Target   Expr
+	Field    string
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *SelectorExpr) exprNode()            {}
+func (s *SelectorExpr) TokenLiteral() string { return s.Field }
+func (s *SelectorExpr) Pos() token.Pos       { return s.StartPos }
+func (s *SelectorExpr) End() token.Pos       { return s.EndPos }
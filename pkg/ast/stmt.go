@@ -0,0 +1,148 @@
+package ast
+
+import "github.com/gunesh/zelang/pkg/token"
+
+// Stmt is implemented by every statement node that can appear in a
+// FunctionDecl/HandlerDecl/IfStmt/ForStmt/WhileStmt body.
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// AssignStmt is `<target> = <value>;` where Target is an lvalue (Ident,
+// IndexExpr, or SelectorExpr).
+type AssignStmt struct {
+	Target   Expr
+	Value    Expr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *AssignStmt) stmtNode()            {}
+func (s *AssignStmt) TokenLiteral() string { return "=" }
+func (s *AssignStmt) Pos() token.Pos       { return s.StartPos }
+func (s *AssignStmt) End() token.Pos       { return s.EndPos }
+
+// TypedDeclStmt is `<type> <name> [= <value>];`, declaring a new variable
+// with an explicit type.
+type TypedDeclStmt struct {
+	Type     string
+	Name     string
+	Value    Expr // nil if the declaration has no initializer
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *TypedDeclStmt) stmtNode()            {}
+func (s *TypedDeclStmt) TokenLiteral() string { return s.Type }
+func (s *TypedDeclStmt) Pos() token.Pos       { return s.StartPos }
+func (s *TypedDeclStmt) End() token.Pos       { return s.EndPos }
+
+// InferredDeclStmt is `<name> := <value>;`, declaring a new variable whose
+// type is inferred from Value.
+type InferredDeclStmt struct {
+	Name     string
+	Value    Expr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *InferredDeclStmt) stmtNode()            {}
+func (s *InferredDeclStmt) TokenLiteral() string { return s.Name }
+func (s *InferredDeclStmt) Pos() token.Pos       { return s.StartPos }
+func (s *InferredDeclStmt) End() token.Pos       { return s.EndPos }
+
+// IfStmt is `if <cond> { <body> } [else ...]`. Else is nil for a bare if;
+// holds exactly one *IfStmt for an "else if" chain; otherwise holds the
+// statements of a plain trailing else block.
+type IfStmt struct {
+	Cond     Expr
+	Body     []Node
+	Else     []Node
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *IfStmt) stmtNode()            {}
+func (s *IfStmt) TokenLiteral() string { return "if" }
+func (s *IfStmt) Pos() token.Pos       { return s.StartPos }
+func (s *IfStmt) End() token.Pos       { return s.EndPos }
+
+// ForStmt is a C-style `for <init>; <cond>; <post> { <body> }` loop. Init,
+// Cond and Post are each independently optional (nil), so `for ;; { }` and
+// `for { }` are both valid infinite loops.
+type ForStmt struct {
+	Init     Node // *InferredDeclStmt, *TypedDeclStmt, *AssignStmt, or nil
+	Cond     Expr // nil means "always true"
+	Post     Node // *AssignStmt, or nil
+	Body     []Node
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *ForStmt) stmtNode()            {}
+func (s *ForStmt) TokenLiteral() string { return "for" }
+func (s *ForStmt) Pos() token.Pos       { return s.StartPos }
+func (s *ForStmt) End() token.Pos       { return s.EndPos }
+
+// WhileStmt is `while <cond> { <body> }`.
+type WhileStmt struct {
+	Cond     Expr
+	Body     []Node
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *WhileStmt) stmtNode()            {}
+func (s *WhileStmt) TokenLiteral() string { return "while" }
+func (s *WhileStmt) Pos() token.Pos       { return s.StartPos }
+func (s *WhileStmt) End() token.Pos       { return s.EndPos }
+
+// ReturnStmt is `return [value];`. Value is nil for a bare return.
+type ReturnStmt struct {
+	Value    Expr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *ReturnStmt) stmtNode()            {}
+func (s *ReturnStmt) TokenLiteral() string { return "return" }
+func (s *ReturnStmt) Pos() token.Pos       { return s.StartPos }
+func (s *ReturnStmt) End() token.Pos       { return s.EndPos }
+
+// BreakStmt is a bare `break;`, valid inside a ForStmt or WhileStmt body.
+type BreakStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *BreakStmt) stmtNode()            {}
+func (s *BreakStmt) TokenLiteral() string { return "break" }
+func (s *BreakStmt) Pos() token.Pos       { return s.StartPos }
+func (s *BreakStmt) End() token.Pos       { return s.EndPos }
+
+// FuncCallStmt is a function call used for its side effects rather than
+// its value, e.g. `log("done");` as a standalone statement.
+type FuncCallStmt struct {
+	Call     *CallExpr
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *FuncCallStmt) stmtNode()            {}
+func (s *FuncCallStmt) TokenLiteral() string { return s.Call.TokenLiteral() }
+func (s *FuncCallStmt) Pos() token.Pos       { return s.StartPos }
+func (s *FuncCallStmt) End() token.Pos       { return s.EndPos }
+
+// EmptyStmt is a bare `;` with no effect - kept as a real node (rather than
+// silently dropped) so source spans stay contiguous for tools that walk
+// statement lists.
+type EmptyStmt struct {
+	StartPos token.Pos
+	EndPos   token.Pos
+}
+
+func (s *EmptyStmt) stmtNode()            {}
+func (s *EmptyStmt) TokenLiteral() string { return ";" }
+func (s *EmptyStmt) Pos() token.Pos       { return s.StartPos }
+func (s *EmptyStmt) End() token.Pos       { return s.EndPos }
@@ -0,0 +1,209 @@
+package ast
+
+import "fmt"
+
+// Visitor's Visit method is invoked for each node encountered by Walk. If
+// the result visitor w is not nil, Walk visits each of the children of
+// node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: it starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w for
+// each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if node == nil {
+		return
+	}
+
+	v = v.Visit(node)
+	if v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkNodes(v, n.Statements)
+
+	case *Decorator:
+		// leaf
+
+	case *FieldDecl:
+		walkDecorators(v, n.Decorators)
+
+	case *StructDecl:
+		walkDecorators(v, n.Decorators)
+		for _, f := range n.Fields {
+			Walk(v, f)
+		}
+
+	case *PageDecl:
+		walkDecorators(v, n.Decorators)
+		walkNodes(v, n.Body)
+
+	case *SectionDecl:
+		walkNodes(v, n.Body)
+
+	case *RowDecl:
+		walkNodes(v, n.Body)
+
+	case *ColumnDecl:
+		walkNodes(v, n.Body)
+
+	case *FormDecl:
+		walkNodes(v, n.Body)
+
+	case *InputDecl:
+		// leaf
+
+	case *ButtonDecl:
+		// leaf
+
+	case *DataListDecl:
+		// leaf
+
+	case *Parameter:
+		// leaf
+
+	case *HandlerDecl:
+		walkDecorators(v, n.Decorators)
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		walkNodes(v, n.Body)
+
+	case *FunctionDecl:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		walkNodes(v, n.Body)
+
+	case *MainDecl:
+		walkNodes(v, n.Body)
+
+	case *HookDecl:
+		// leaf
+
+	// Statements
+	case *AssignStmt:
+		Walk(v, n.Target)
+		Walk(v, n.Value)
+
+	case *TypedDeclStmt:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	case *InferredDeclStmt:
+		Walk(v, n.Value)
+
+	case *IfStmt:
+		Walk(v, n.Cond)
+		walkNodes(v, n.Body)
+		walkNodes(v, n.Else)
+
+	case *ForStmt:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+		if n.Post != nil {
+			Walk(v, n.Post)
+		}
+		walkNodes(v, n.Body)
+
+	case *WhileStmt:
+		Walk(v, n.Cond)
+		walkNodes(v, n.Body)
+
+	case *ReturnStmt:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	case *BreakStmt:
+		// leaf
+
+	case *FuncCallStmt:
+		Walk(v, n.Call)
+
+	case *EmptyStmt:
+		// leaf
+
+	// Expressions
+	case *Ident:
+		// leaf
+
+	case *IntLit:
+		// leaf
+
+	case *FloatLit:
+		// leaf
+
+	case *StringLit:
+		// leaf
+
+	case *BoolLit:
+		// leaf
+
+	case *BinaryExpr:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *UnaryExpr:
+		Walk(v, n.Operand)
+
+	case *CallExpr:
+		Walk(v, n.Callee)
+		for _, a := range n.Args {
+			Walk(v, a)
+		}
+
+	case *IndexExpr:
+		Walk(v, n.Target)
+		Walk(v, n.Index)
+
+	case *SelectorExpr:
+		Walk(v, n.Target)
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkNodes(v Visitor, nodes []Node) {
+	for _, n := range nodes {
+		Walk(v, n)
+	}
+}
+
+func walkDecorators(v Visitor, decorators []*Decorator) {
+	for _, d := range decorators {
+		Walk(v, d)
+	}
+}
+
+// inspector adapts a func(Node) bool to the Visitor interface for Inspect.
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: it starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
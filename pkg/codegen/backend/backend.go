@@ -0,0 +1,217 @@
+// Package backend defines the pluggable target interface that the codegen
+// package renders through. Each backend owns the dialect decisions (host
+// type mapping, SQL type mapping, constraint syntax) and the template set
+// needed to emit a full target stack, so codegen itself stays target-agnostic.
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// OutputFormat is a page's declared output representation. A page can
+// declare more than one (e.g. html + json) and the generator renders one
+// body function plus one route per format.
+type OutputFormat string
+
+const (
+	FormatHTML OutputFormat = "html"
+	FormatJSON OutputFormat = "json"
+	FormatCSV  OutputFormat = "csv"
+	FormatXML  OutputFormat = "xml"
+)
+
+var knownFormats = map[OutputFormat]bool{
+	FormatHTML: true,
+	FormatJSON: true,
+	FormatCSV:  true,
+	FormatXML:  true,
+}
+
+// NormalizeFormat maps a page's declared format name to a known
+// OutputFormat, falling back to FormatHTML for anything ambiguous or
+// unrecognized (mirrors the rule that a plain-text partial can only be
+// included from a plain-text parent: unknown formats never accidentally
+// inherit another format's template).
+func NormalizeFormat(name string) OutputFormat {
+	f := OutputFormat(strings.ToLower(strings.TrimSpace(name)))
+	if knownFormats[f] {
+		return f
+	}
+	return FormatHTML
+}
+
+// ContentType returns the HTTP Content-Type a format's body is served with.
+func ContentType(format OutputFormat) string {
+	switch format {
+	case FormatJSON:
+		return "application/json"
+	case FormatCSV:
+		return "text/csv"
+	case FormatXML:
+		return "application/xml"
+	default:
+		return "text/html"
+	}
+}
+
+// FieldData is the per-field view handed to a backend's templates.
+type FieldData struct {
+	Name            string
+	HostType        string // backend-mapped type, e.g. C's "char*" or Go's "string"
+	SQLType         string
+	Constraints     string
+	Title           string
+	IsBool          bool
+	IsArray         bool
+	IsAutoIncrement bool
+}
+
+// ParamData describes a single function parameter in the backend's host language.
+type ParamData struct {
+	Type string
+	Name string
+}
+
+// FormFieldData describes a single input field rendered in a generated form.
+type FormFieldData struct {
+	Name      string
+	Label     string
+	InputType string
+	Required  bool
+}
+
+// StructData is handed to RenderStruct.
+type StructData struct {
+	StructName string
+	Fields     []FieldData
+}
+
+// CRUDTemplateData is handed to RenderCRUD.
+type CRUDTemplateData struct {
+	StructName   string
+	TableName    string
+	Params       []ParamData
+	BindFields   []FieldData
+	AllFields    []FieldData
+	Fields       []FieldData
+	FieldNames   string
+	Placeholders string
+}
+
+// HTMLTemplateData describes a single page + backing struct pair, rendered
+// once per declared OutputFormat.
+type HTMLTemplateData struct {
+	PageNameLower string
+	PageTitle     string
+	HasDataList   bool
+	HasForm       bool
+	StructName    string
+	TableName     string
+	Fields        []FieldData
+	FormFields    []FormFieldData
+
+	// Route is the URL path this page's default (HTML) view is served
+	// from, e.g. "/" or "/about".
+	Route string
+
+	// Formats lists every output format the page declared (always
+	// contains at least FormatHTML).
+	Formats []OutputFormat
+	// Format is the format currently being rendered; FuncName is the
+	// body-render function name for that format.
+	Format   OutputFormat
+	FuncName string
+}
+
+// FormatRoute is a single non-default route+render-function pairing for a
+// page's extra (non-HTML) output format.
+type FormatRoute struct {
+	Format      OutputFormat
+	Route       string
+	ContentType string
+	RenderFunc  string
+}
+
+// HandlerData is the route-handling counterpart to HTMLTemplateData: one per
+// page, dispatched by Route inside the shared handle_request function.
+type HandlerData struct {
+	StructName    string
+	TableName     string
+	PageNameLower string
+	Route         string
+	FormFields    []FieldData
+
+	// ExtraFormats holds the page's non-HTML output formats.
+	ExtraFormats []FormatRoute
+}
+
+// StructSummary is the minimal struct info needed to bootstrap tables in a
+// generated main/server entrypoint.
+type StructSummary struct {
+	Name string
+}
+
+// WebServerData is handed to RenderWebServer. Pages/Handlers hold one entry
+// per PageDecl the program declares, in declaration order.
+type WebServerData struct {
+	Pages    []HTMLTemplateData
+	Handlers []HandlerData
+	Structs  []StructSummary
+}
+
+// Backend generates output for one target stack (host language, storage
+// engine, and web framework). Implementations live under
+// pkg/codegen/backends/<name> and register themselves via Register in an
+// init function.
+type Backend interface {
+	// Name identifies the backend, e.g. "c-sqlite-microhttpd".
+	Name() string
+
+	// Headers returns the boilerplate written once at the top of the
+	// generated output (includes, global state, etc).
+	Headers(hasWeb bool) string
+
+	// RenderStruct renders the data type declaration described by data.
+	RenderStruct(data StructData) (string, error)
+
+	// RenderCRUD renders the create/find/all/delete/init-table functions
+	// described by data.
+	RenderCRUD(data CRUDTemplateData) (string, error)
+
+	// RenderWebServer renders the HTML rendering, route handling and
+	// server bootstrap code described by data.
+	RenderWebServer(data WebServerData) (string, error)
+
+	// MapType maps a zelang field type to this backend's host-language type.
+	MapType(zlType string) string
+
+	// MapSQLType maps a zelang field type to this backend's SQL dialect type.
+	MapSQLType(zlType string) string
+
+	// FieldConstraints renders the SQL constraints implied by field's decorators.
+	FieldConstraints(field *ast.FieldDecl) string
+}
+
+// Factory constructs a Backend instance with its template set ready to
+// execute (a backend may reuse a set parsed earlier, see CachedTemplate).
+type Factory func() (Backend, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a backend available under name. It is meant to be called
+// from a backend package's init function.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// Get constructs the backend registered under name.
+func Get(name string) (Backend, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown codegen backend %q", name)
+	}
+	return factory()
+}
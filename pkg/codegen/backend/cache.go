@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"sync"
+	"text/template"
+)
+
+// templateCacheEntry lazily parses its template exactly once per process,
+// however many times CachedTemplate is called for its key.
+type templateCacheEntry struct {
+	once sync.Once
+	tmpl *template.Template
+	err  error
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]*templateCacheEntry{}
+)
+
+// CachedTemplate returns the *template.Template registered under key,
+// invoking parse at most once per process for that key. key is
+// conventionally "<backend-name>@<version>" so bumping a backend's version
+// gets a fresh parse instead of reusing a stale one. This matters once
+// NewTemplateGenerator is called repeatedly in the same process (LSP, watch
+// mode, tests) instead of once per CLI invocation.
+func CachedTemplate(key string, parse func() (*template.Template, error)) (*template.Template, error) {
+	cacheMu.Lock()
+	entry, ok := cache[key]
+	if !ok {
+		entry = &templateCacheEntry{}
+		cache[key] = entry
+	}
+	cacheMu.Unlock()
+
+	entry.once.Do(func() {
+		entry.tmpl, entry.err = parse()
+	})
+	return entry.tmpl, entry.err
+}
@@ -0,0 +1,226 @@
+// Package csqlite is the default codegen backend: C structs and CRUD
+// functions backed by SQLite, with an optional microhttpd web server.
+package csqlite
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/codegen/backend"
+	"github.com/gunesh/zelang/pkg/decorator"
+)
+
+// Name is the identifier this backend registers under.
+const Name = "c-sqlite-microhttpd"
+
+// version changes whenever the template set or funcMap changes shape;
+// bumping it gets a fresh parse out of backend.CachedTemplate instead of
+// reusing one cut from an older layout.
+const version = "v1"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+func init() {
+	backend.Register(Name, New)
+}
+
+type csqliteBackend struct {
+	templates *template.Template
+}
+
+// New returns a backend.Backend backed by this package's template set. The
+// set is parsed once per process via backend.CachedTemplate, since New is
+// typically called on every NewTemplateGenerator invocation (watch mode,
+// LSP, tests all construct many generators in one process).
+func New() (backend.Backend, error) {
+	tmpl, err := backend.CachedTemplate(Name+"@"+version, parseTemplates)
+	if err != nil {
+		return nil, err
+	}
+	return &csqliteBackend{templates: tmpl}, nil
+}
+
+func parseTemplates() (*template.Template, error) {
+	// funcMap binds each template function name to a small shim that
+	// dereferences funcImpls on every call, so SetFunc can swap an
+	// implementation without forcing a re-parse of the (now cached)
+	// template tree.
+	funcMap := template.FuncMap{
+		"add":   callAdd,
+		"title": callTitle,
+	}
+
+	tmpl, err := template.New("").Funcs(funcMap).ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("csqlite: failed to parse templates: %w", err)
+	}
+	return tmpl, nil
+}
+
+var funcImpls = struct {
+	mu    sync.RWMutex
+	impls map[string]interface{}
+}{impls: map[string]interface{}{
+	"add":   func(a, b int) int { return a + b },
+	"title": strings.Title,
+}}
+
+// SetFunc overrides the implementation behind a template function name at
+// execution time, without re-parsing the cached template tree.
+func SetFunc(name string, impl interface{}) {
+	funcImpls.mu.Lock()
+	defer funcImpls.mu.Unlock()
+	funcImpls.impls[name] = impl
+}
+
+func callAdd(a, b int) int {
+	funcImpls.mu.RLock()
+	defer funcImpls.mu.RUnlock()
+	return funcImpls.impls["add"].(func(int, int) int)(a, b)
+}
+
+func callTitle(s string) string {
+	funcImpls.mu.RLock()
+	defer funcImpls.mu.RUnlock()
+	return funcImpls.impls["title"].(func(string) string)(s)
+}
+
+func (b *csqliteBackend) Name() string { return Name }
+
+func (b *csqliteBackend) Headers(hasWeb bool) string {
+	var out bytes.Buffer
+
+	out.WriteString(`#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sqlite3.h>
+`)
+	if hasWeb {
+		out.WriteString(`#include <ctype.h>
+#include <microhttpd.h>
+`)
+	}
+	out.WriteString(`
+// Global database connection
+sqlite3 *db = NULL;
+
+`)
+	if hasWeb {
+		out.WriteString(`// Global HTTP server
+struct MHD_Daemon *http_daemon = NULL;
+
+`)
+	}
+
+	return out.String()
+}
+
+func (b *csqliteBackend) RenderStruct(data backend.StructData) (string, error) {
+	var out bytes.Buffer
+	if err := b.templates.ExecuteTemplate(&out, "struct_def.tmpl", data); err != nil {
+		return "", fmt.Errorf("csqlite: struct_def.tmpl: %w", err)
+	}
+	return out.String(), nil
+}
+
+func (b *csqliteBackend) RenderCRUD(data backend.CRUDTemplateData) (string, error) {
+	var out bytes.Buffer
+	for _, name := range []string{
+		"crud_create.tmpl",
+		"crud_find.tmpl",
+		"crud_all.tmpl",
+		"crud_delete.tmpl",
+		"crud_init_table.tmpl",
+	} {
+		if err := b.templates.ExecuteTemplate(&out, name, data); err != nil {
+			return "", fmt.Errorf("csqlite: %s: %w", name, err)
+		}
+		out.WriteString("\n\n")
+	}
+	return out.String(), nil
+}
+
+func (b *csqliteBackend) RenderWebServer(data backend.WebServerData) (string, error) {
+	var out bytes.Buffer
+
+	if err := b.templates.ExecuteTemplate(&out, "html_header.tmpl", nil); err != nil {
+		return "", fmt.Errorf("csqlite: html_header.tmpl: %w", err)
+	}
+	out.WriteString("\n")
+
+	for _, page := range data.Pages {
+		for _, format := range page.Formats {
+			rendered := page
+			rendered.Format = format
+			if format == backend.FormatHTML {
+				rendered.FuncName = fmt.Sprintf("render_%s_page", page.PageNameLower)
+			} else {
+				rendered.FuncName = fmt.Sprintf("render_%s_%s_page", page.PageNameLower, format)
+			}
+
+			templateName := fmt.Sprintf("page.%s.tmpl", format)
+			if err := b.templates.ExecuteTemplate(&out, templateName, rendered); err != nil {
+				return "", fmt.Errorf("csqlite: %s: %w", templateName, err)
+			}
+			out.WriteString("\n\n")
+		}
+	}
+
+	if len(data.Handlers) > 0 {
+		if err := b.templates.ExecuteTemplate(&out, "http_handler.tmpl", data.Handlers); err != nil {
+			return "", fmt.Errorf("csqlite: http_handler.tmpl: %w", err)
+		}
+		out.WriteString("\n\n")
+	}
+
+	mainData := struct{ Structs []backend.StructSummary }{Structs: data.Structs}
+	if err := b.templates.ExecuteTemplate(&out, "web_main.tmpl", mainData); err != nil {
+		return "", fmt.Errorf("csqlite: web_main.tmpl: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+func (b *csqliteBackend) MapType(zlType string) string {
+	switch zlType {
+	case "int":
+		return "int64_t"
+	case "float":
+		return "double"
+	case "string":
+		return "char*"
+	case "bool":
+		return "int"
+	case "date", "datetime":
+		return "char*"
+	default:
+		return zlType
+	}
+}
+
+func (b *csqliteBackend) MapSQLType(zlType string) string {
+	switch zlType {
+	case "int":
+		return "INTEGER"
+	case "float":
+		return "REAL"
+	case "string":
+		return "TEXT"
+	case "bool":
+		return "INTEGER"
+	case "date", "datetime":
+		return "TEXT"
+	default:
+		return "TEXT"
+	}
+}
+
+func (b *csqliteBackend) FieldConstraints(field *ast.FieldDecl) string {
+	return decorator.Constraints(field.Decorators, "sqlite")
+}
@@ -5,25 +5,51 @@ import (
 	"strings"
 
 	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/decorator"
 )
 
 type CGenerator struct {
-	structs  []*ast.StructDecl
-	pages    []*ast.PageDecl
-	handlers []*ast.HandlerDecl
-	output   strings.Builder
-	hasWeb   bool
+	structs     []*ast.StructDecl
+	pages       []*ast.PageDecl
+	handlers    []*ast.HandlerDecl
+	hooks       []*ast.HookDecl
+	functions   []*ast.FunctionDecl
+	output      strings.Builder
+	hasWeb      bool
+	db          SQLBackend
+	dsn         string
+	authEnabled bool
 }
 
 func New() *CGenerator {
+	db, _ := newSQLBackend(DefaultSQLBackend)
 	return &CGenerator{
-		structs:  []*ast.StructDecl{},
-		pages:    []*ast.PageDecl{},
-		handlers: []*ast.HandlerDecl{},
-		hasWeb:   false,
+		structs:   []*ast.StructDecl{},
+		pages:     []*ast.PageDecl{},
+		handlers:  []*ast.HandlerDecl{},
+		hooks:     []*ast.HookDecl{},
+		functions: []*ast.FunctionDecl{},
+		hasWeb:    false,
+		db:        db,
+		dsn:       defaultDSN(DefaultSQLBackend),
 	}
 }
 
+// NewWithDatabase creates a CGenerator targeting the named SQL dialect
+// ("sqlite", "mysql", "postgres"), e.g. from a CLI flag. A program's own
+// top-level @database(...) decorator still wins, the same way @table always
+// overrides a generator's default table-naming convention.
+func NewWithDatabase(name string) (*CGenerator, error) {
+	db, err := newSQLBackend(name)
+	if err != nil {
+		return nil, err
+	}
+	g := New()
+	g.db = db
+	g.dsn = defaultDSN(name)
+	return g, nil
+}
+
 func (g *CGenerator) Generate(program *ast.Program) string {
 	g.output.Reset()
 
@@ -38,11 +64,36 @@ func (g *CGenerator) Generate(program *ast.Program) string {
 		case *ast.HandlerDecl:
 			g.handlers = append(g.handlers, s)
 			g.hasWeb = true
+		case *ast.HookDecl:
+			g.hooks = append(g.hooks, s)
+		case *ast.FunctionDecl:
+			g.functions = append(g.functions, s)
 		}
 	}
 
+	g.resolveDatabaseBackend()
+	g.authEnabled = g.resolveAuth()
+
+	// Best-effort: diff the current schema against .zelang/schema.json and
+	// write out a new numbered migration if anything changed. Migrations are
+	// filesystem-backed, so a read-only or missing project directory just
+	// means no migration gets recorded this build rather than failing codegen.
+	// @storage("fs", ...) structs have no SQL table to migrate, so they're
+	// excluded the same way FTS/hooks exclude non-sqlite dialects.
+	g.GenerateMigration(g.sqlStructs())
+
 	// Generate headers
 	g.generateHeaders()
+	g.generateQueryRuntime()
+	g.generateMigrationsRuntime()
+	g.generateHookCallbacks()
+	g.generateCustomFunctions()
+	g.generateFSRuntime()
+	g.generateJSONRuntime()
+	g.generateAuthRuntime()
+	g.generateUploadRuntime()
+	g.generateGraphQLRuntime()
+	g.generateDaemonRuntime()
 
 	// Generate struct definitions
 	for _, s := range g.structs {
@@ -69,18 +120,38 @@ func (g *CGenerator) generateHeaders() {
 	g.output.WriteString(`#include <stdio.h>
 #include <stdlib.h>
 #include <string.h>
-#include <sqlite3.h>
 `)
+	g.output.WriteString(g.db.Headers())
 	if g.hasWeb {
 		g.output.WriteString(`#include <ctype.h>
 #include <microhttpd.h>
+#include <signal.h>
+#include <sys/types.h>
+#include <sys/stat.h>
+#include <unistd.h>
 `)
 	}
-	g.output.WriteString(`
-// Global database connection
-sqlite3 *db = NULL;
-
+	if g.usesFSStorage() {
+		g.output.WriteString(`#include <sys/stat.h>
+#include <dirent.h>
+#include <unistd.h>
 `)
+	}
+	if g.authEnabled {
+		g.output.WriteString(`#include <stdint.h>
+#include <time.h>
+#include <fcntl.h>
+#include <unistd.h>
+`)
+	}
+	if g.usesFileUploads() {
+		g.output.WriteString(`#include <sys/stat.h>
+#include <time.h>
+`)
+	}
+	g.output.WriteString("\n// Global database connection\n")
+	g.output.WriteString(g.db.GlobalVarDecl())
+	g.output.WriteString("\n")
 	if g.hasWeb {
 		g.output.WriteString(`// Global HTTP server
 struct MHD_Daemon *http_daemon = NULL;
@@ -89,6 +160,45 @@ struct MHD_Daemon *http_daemon = NULL;
 	}
 }
 
+// resolveDatabaseBackend looks for a @database("dialect", "dsn") decorator
+// and switches g.db/g.dsn to match it. The parser only attaches decorators
+// to the struct/page/handler immediately following them, so a program-level
+// @database is expected to decorate its first struct; a generator created
+// via NewWithDatabase (e.g. from a CLI flag) is used when none is found.
+func (g *CGenerator) resolveDatabaseBackend() {
+	for _, s := range g.structs {
+		for _, dec := range s.Decorators {
+			if dec.Name != "database" || len(dec.Args) == 0 {
+				continue
+			}
+			name := strings.Trim(dec.Args[0], `"`)
+			db, err := newSQLBackend(name)
+			if err != nil {
+				return
+			}
+			g.db = db
+			if len(dec.Args) > 1 {
+				g.dsn = strings.Trim(dec.Args[1], `"`)
+			} else {
+				g.dsn = defaultDSN(name)
+			}
+			return
+		}
+	}
+}
+
+// nonArrayFields returns s's scalar fields in declaration order, i.e. the
+// ones that map to table columns.
+func nonArrayFields(s *ast.StructDecl) []*ast.FieldDecl {
+	fields := []*ast.FieldDecl{}
+	for _, field := range s.Fields {
+		if !field.IsArray {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
 func (g *CGenerator) generateStruct(s *ast.StructDecl) {
 	g.output.WriteString(fmt.Sprintf("// Struct: %s\n", s.Name))
 	g.output.WriteString(fmt.Sprintf("typedef struct %s {\n", s.Name))
@@ -118,6 +228,8 @@ func (g *CGenerator) mapType(zlType string) string {
 		return "int"
 	case "date", "datetime":
 		return "char*"
+	case "file", "blob":
+		return "char*"
 	default:
 		return zlType
 	}
@@ -126,6 +238,16 @@ func (g *CGenerator) mapType(zlType string) string {
 func (g *CGenerator) generateCRUD(s *ast.StructDecl) {
 	tableName := g.getTableName(s)
 
+	// JSON (de)serialization backs the /api/{table} REST surface regardless
+	// of which storage backend the struct uses.
+	g.generateToJSON(s)
+	g.generateFromJSON(s)
+
+	if dir, ok := fsStorageDir(s); ok {
+		g.generateFSCRUD(s, tableName, dir)
+		return
+	}
+
 	// Generate CREATE TABLE function
 	g.generateInitTable(s, tableName)
 
@@ -140,6 +262,15 @@ func (g *CGenerator) generateCRUD(s *ast.StructDecl) {
 
 	// Generate DELETE function
 	g.generateDelete(s, tableName)
+
+	// Generate UPDATE function
+	g.generateUpdate(s, tableName)
+
+	// Generate chainable query builder (Struct_query/_where_.../_exec)
+	g.generateQueryBuilder(s, tableName)
+
+	// Generate full-text search over @searchable fields, if any
+	g.generateSearch(s, tableName)
 }
 
 func (g *CGenerator) getTableName(s *ast.StructDecl) string {
@@ -163,8 +294,9 @@ func (g *CGenerator) generateInitTable(s *ast.StructDecl, tableName string) {
 			continue
 		}
 
-		sqlType := g.mapSQLType(field.Type)
-		constraints := g.getFieldConstraints(field)
+		isAuto := decorator.IsAutoIncrement(field.Decorators) && decorator.IsPrimaryKey(field.Decorators)
+		sqlType := g.db.MapSQLType(field.Type, isAuto)
+		constraints := decorator.Constraints(field.Decorators, g.db.Name())
 		fields = append(fields, fmt.Sprintf("        \"%s %s%s\"", field.Name, sqlType, constraints))
 	}
 
@@ -179,51 +311,14 @@ func (g *CGenerator) generateInitTable(s *ast.StructDecl, tableName string) {
 
 	g.output.WriteString("        \")\";\n")
 	g.output.WriteString("    \n")
-	g.output.WriteString("    char *err_msg = NULL;\n")
-	g.output.WriteString("    int rc = sqlite3_exec(db, sql, NULL, NULL, &err_msg);\n")
-	g.output.WriteString("    if (rc != SQLITE_OK) {\n")
-	g.output.WriteString("        fprintf(stderr, \"SQL error: %s\\n\", err_msg);\n")
-	g.output.WriteString("        sqlite3_free(err_msg);\n")
-	g.output.WriteString("    } else {\n")
-	g.output.WriteString(fmt.Sprintf("        printf(\"Table %s created successfully\\n\");\n", tableName))
+	g.output.WriteString("    {\n")
+	g.output.WriteString("    " + g.db.ExecDDLSnippet("sql") + "\n")
+	g.output.WriteString(fmt.Sprintf("    if (rc == 0) printf(\"Table %s created successfully\\n\");\n", tableName))
 	g.output.WriteString("    }\n")
-	g.output.WriteString("}\n\n")
-}
 
-func (g *CGenerator) mapSQLType(zlType string) string {
-	switch zlType {
-	case "int":
-		return "INTEGER"
-	case "float":
-		return "REAL"
-	case "string":
-		return "TEXT"
-	case "bool":
-		return "INTEGER"
-	case "date", "datetime":
-		return "TEXT"
-	default:
-		return "TEXT"
-	}
-}
-
-func (g *CGenerator) getFieldConstraints(field *ast.FieldDecl) string {
-	constraints := ""
+	g.generateFTSIndex(s, tableName)
 
-	for _, dec := range field.Decorators {
-		switch dec.Name {
-		case "primary":
-			constraints += " PRIMARY KEY"
-		case "autoincrement":
-			constraints += " AUTOINCREMENT"
-		case "required":
-			constraints += " NOT NULL"
-		case "unique":
-			constraints += " UNIQUE"
-		}
-	}
-
-	return constraints
+	g.output.WriteString("}\n\n")
 }
 
 func (g *CGenerator) generateCreate(s *ast.StructDecl, tableName string) {
@@ -236,18 +331,12 @@ func (g *CGenerator) generateCreate(s *ast.StructDecl, tableName string) {
 		if field.IsArray {
 			continue
 		}
-		// Skip auto fields
-		isAuto := false
-		for _, dec := range field.Decorators {
-			if dec.Name == "autoincrement" || dec.Name == "timestamp" {
-				isAuto = true
-			}
-		}
-		if !isAuto {
-			cType := g.mapType(field.Type)
-			params = append(params, fmt.Sprintf("%s %s", cType, field.Name))
-			nonAutoFields = append(nonAutoFields, field)
+		if decorator.IsAutoIncrement(field.Decorators) {
+			continue
 		}
+		cType := g.mapType(field.Type)
+		params = append(params, fmt.Sprintf("%s %s", cType, field.Name))
+		nonAutoFields = append(nonAutoFields, field)
 	}
 	g.output.WriteString(strings.Join(params, ", "))
 	g.output.WriteString(") {\n")
@@ -264,46 +353,42 @@ func (g *CGenerator) generateCreate(s *ast.StructDecl, tableName string) {
 	g.output.WriteString(") VALUES (")
 
 	placeholders := []string{}
-	for range nonAutoFields {
-		placeholders = append(placeholders, "?")
+	for i := range nonAutoFields {
+		placeholders = append(placeholders, g.db.Placeholder(i+1))
 	}
 	g.output.WriteString(strings.Join(placeholders, ", "))
-	g.output.WriteString(")\");\n\n")
+	g.output.WriteString(")")
+	g.output.WriteString(g.db.InsertReturningClause())
+	g.output.WriteString("\");\n\n")
 
 	// Prepare statement
-	g.output.WriteString("    sqlite3_stmt *stmt;\n")
-	g.output.WriteString("    int rc = sqlite3_prepare_v2(db, sql, -1, &stmt, NULL);\n")
-	g.output.WriteString("    if (rc != SQLITE_OK) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Failed to prepare statement: %s\\n\", sqlite3_errmsg(db));\n")
+	g.output.WriteString("    " + g.db.StmtVarDecl("stmt", len(nonAutoFields)))
+	g.output.WriteString("    " + g.db.PrepareSnippet("stmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to prepare statement: %%s\\n\", %s);\n", g.db.ErrorExpr()))
 	g.output.WriteString("        return NULL;\n")
 	g.output.WriteString("    }\n\n")
 
 	// Bind parameters
 	for i, field := range nonAutoFields {
-		bindIndex := i + 1
 		cType := g.mapType(field.Type)
-
-		switch cType {
-		case "int64_t":
-			g.output.WriteString(fmt.Sprintf("    sqlite3_bind_int64(stmt, %d, %s);\n", bindIndex, field.Name))
-		case "double":
-			g.output.WriteString(fmt.Sprintf("    sqlite3_bind_double(stmt, %d, %s);\n", bindIndex, field.Name))
-		case "char*":
-			g.output.WriteString(fmt.Sprintf("    sqlite3_bind_text(stmt, %d, %s, -1, SQLITE_TRANSIENT);\n", bindIndex, field.Name))
+		if snippet := g.db.BindSnippet("stmt", i, cType, field.Name); snippet != "" {
+			g.output.WriteString("    " + snippet + "\n")
 		}
 	}
 
 	// Execute
-	g.output.WriteString("\n    rc = sqlite3_step(stmt);\n")
-	g.output.WriteString("    if (rc != SQLITE_DONE) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Failed to insert: %s\\n\", sqlite3_errmsg(db));\n")
-	g.output.WriteString("        sqlite3_finalize(stmt);\n")
+	g.output.WriteString("\n    " + g.db.ExecSnippet("stmt", len(nonAutoFields)) + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to insert: %%s\\n\", %s);\n", g.db.ErrorExpr()))
+	g.output.WriteString("        " + g.db.FinalizeSnippet("stmt"))
 	g.output.WriteString("        return NULL;\n")
 	g.output.WriteString("    }\n\n")
 
 	// Get last insert ID
-	g.output.WriteString("    int64_t last_insert_id = sqlite3_last_insert_rowid(db);\n")
-	g.output.WriteString("    sqlite3_finalize(stmt);\n\n")
+	g.output.WriteString(fmt.Sprintf("    int64_t last_insert_id = %s;\n", g.db.LastInsertIDSnippet("stmt")))
+	g.output.WriteString("    " + g.db.FinalizeSnippet("stmt"))
+	g.output.WriteString("\n")
 
 	// Create and populate struct
 	g.output.WriteString(fmt.Sprintf("    %s* obj = (%s*)malloc(sizeof(%s));\n", s.Name, s.Name, s.Name))
@@ -343,20 +428,27 @@ func (g *CGenerator) generateFind(s *ast.StructDecl, tableName string) {
 	g.output.WriteString(fmt.Sprintf("%s* %s_find(int64_t id) {\n", s.Name, s.Name))
 
 	// Build SELECT query
-	g.output.WriteString(fmt.Sprintf("    char *sql = \"SELECT * FROM %s WHERE id = ?\";\n", tableName))
-	g.output.WriteString("    sqlite3_stmt *stmt;\n\n")
+	g.output.WriteString(fmt.Sprintf("    char *sql = \"SELECT * FROM %s WHERE id = %s\";\n", tableName, g.db.Placeholder(1)))
+	g.output.WriteString("    " + g.db.StmtVarDecl("stmt", 1) + "\n")
 
-	g.output.WriteString("    int rc = sqlite3_prepare_v2(db, sql, -1, &stmt, NULL);\n")
-	g.output.WriteString("    if (rc != SQLITE_OK) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Failed to prepare statement: %s\\n\", sqlite3_errmsg(db));\n")
+	g.output.WriteString("    " + g.db.PrepareSnippet("stmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to prepare statement: %%s\\n\", %s);\n", g.db.ErrorExpr()))
 	g.output.WriteString("        return NULL;\n")
 	g.output.WriteString("    }\n\n")
 
-	g.output.WriteString("    sqlite3_bind_int64(stmt, 1, id);\n\n")
+	if snippet := g.db.BindSnippet("stmt", 0, "int64_t", "id"); snippet != "" {
+		g.output.WriteString("    " + snippet + "\n\n")
+	}
 
-	g.output.WriteString("    rc = sqlite3_step(stmt);\n")
-	g.output.WriteString("    if (rc != SQLITE_ROW) {\n")
-	g.output.WriteString("        sqlite3_finalize(stmt);\n")
+	fields := nonArrayFields(s)
+	if rv := g.db.ResultVarDecl("stmt", fields); rv != "" {
+		g.output.WriteString("    " + rv + "\n\n")
+	}
+
+	g.output.WriteString("    " + g.db.StepRowSnippet("stmt") + "\n")
+	g.output.WriteString("    if (rc != 1) {\n")
+	g.output.WriteString("        " + g.db.FinalizeSnippet("stmt"))
 	g.output.WriteString("        return NULL;\n")
 	g.output.WriteString("    }\n\n")
 
@@ -364,25 +456,11 @@ func (g *CGenerator) generateFind(s *ast.StructDecl, tableName string) {
 	g.output.WriteString(fmt.Sprintf("    %s* obj = (%s*)malloc(sizeof(%s));\n", s.Name, s.Name, s.Name))
 
 	// Read columns
-	colIndex := 0
-	for _, field := range s.Fields {
-		if field.IsArray {
-			continue
-		}
-
-		cType := g.mapType(field.Type)
-		switch cType {
-		case "int64_t":
-			g.output.WriteString(fmt.Sprintf("    obj->%s = sqlite3_column_int64(stmt, %d);\n", field.Name, colIndex))
-		case "double":
-			g.output.WriteString(fmt.Sprintf("    obj->%s = sqlite3_column_double(stmt, %d);\n", field.Name, colIndex))
-		case "char*":
-			g.output.WriteString(fmt.Sprintf("    obj->%s = strdup((const char*)sqlite3_column_text(stmt, %d));\n", field.Name, colIndex))
-		}
-		colIndex++
+	for colIndex, field := range fields {
+		g.output.WriteString(fmt.Sprintf("    obj->%s = %s;\n", field.Name, g.db.ColumnReadSnippet("stmt", field, colIndex)))
 	}
 
-	g.output.WriteString("\n    sqlite3_finalize(stmt);\n")
+	g.output.WriteString("\n    " + g.db.FinalizeSnippet("stmt"))
 	g.output.WriteString("    return obj;\n")
 	g.output.WriteString("}\n\n")
 }
@@ -391,22 +469,28 @@ func (g *CGenerator) generateAll(s *ast.StructDecl, tableName string) {
 	g.output.WriteString(fmt.Sprintf("%s** %s_all(int* count) {\n", s.Name, s.Name))
 
 	g.output.WriteString(fmt.Sprintf("    char *sql = \"SELECT * FROM %s\";\n", tableName))
-	g.output.WriteString("    sqlite3_stmt *stmt;\n\n")
+	g.output.WriteString("    " + g.db.StmtVarDecl("stmt", 0) + "\n")
 
-	g.output.WriteString("    int rc = sqlite3_prepare_v2(db, sql, -1, &stmt, NULL);\n")
-	g.output.WriteString("    if (rc != SQLITE_OK) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Failed to prepare statement: %s\\n\", sqlite3_errmsg(db));\n")
+	g.output.WriteString("    " + g.db.PrepareSnippet("stmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to prepare statement: %%s\\n\", %s);\n", g.db.ErrorExpr()))
 	g.output.WriteString("        *count = 0;\n")
 	g.output.WriteString("        return NULL;\n")
 	g.output.WriteString("    }\n\n")
 
+	fields := nonArrayFields(s)
+	if rv := g.db.ResultVarDecl("stmt", fields); rv != "" {
+		g.output.WriteString("    " + rv + "\n\n")
+	}
+
 	// Allocate array
 	g.output.WriteString("    int capacity = 10;\n")
 	g.output.WriteString(fmt.Sprintf("    %s** results = (%s**)malloc(capacity * sizeof(%s*));\n", s.Name, s.Name, s.Name))
 	g.output.WriteString("    int n = 0;\n\n")
 
 	// Fetch all rows
-	g.output.WriteString("    while ((rc = sqlite3_step(stmt)) == SQLITE_ROW) {\n")
+	g.output.WriteString("    " + g.db.StepRowSnippet("stmt") + "\n")
+	g.output.WriteString("    while (rc == 1) {\n")
 	g.output.WriteString("        if (n >= capacity) {\n")
 	g.output.WriteString("            capacity *= 2;\n")
 	g.output.WriteString(fmt.Sprintf("            results = (%s**)realloc(results, capacity * sizeof(%s*));\n", s.Name, s.Name))
@@ -416,28 +500,18 @@ func (g *CGenerator) generateAll(s *ast.StructDecl, tableName string) {
 	g.output.WriteString(fmt.Sprintf("        %s* obj = (%s*)malloc(sizeof(%s));\n", s.Name, s.Name, s.Name))
 
 	// Read columns
-	colIndex := 0
-	for _, field := range s.Fields {
-		if field.IsArray {
-			continue
-		}
-
-		cType := g.mapType(field.Type)
-		switch cType {
-		case "int64_t":
-			g.output.WriteString(fmt.Sprintf("        obj->%s = sqlite3_column_int64(stmt, %d);\n", field.Name, colIndex))
-		case "double":
-			g.output.WriteString(fmt.Sprintf("        obj->%s = sqlite3_column_double(stmt, %d);\n", field.Name, colIndex))
-		case "char*":
-			g.output.WriteString(fmt.Sprintf("        obj->%s = strdup((const char*)sqlite3_column_text(stmt, %d));\n", field.Name, colIndex))
-		}
-		colIndex++
+	for colIndex, field := range fields {
+		g.output.WriteString(fmt.Sprintf("        obj->%s = %s;\n", field.Name, g.db.ColumnReadSnippet("stmt", field, colIndex)))
 	}
 
 	g.output.WriteString("\n        results[n++] = obj;\n")
+	if adv := g.db.AdvanceRowSnippet("stmt"); adv != "" {
+		g.output.WriteString("        " + adv)
+	}
+	g.output.WriteString("        " + g.db.StepRowSnippet("stmt") + "\n")
 	g.output.WriteString("    }\n\n")
 
-	g.output.WriteString("    sqlite3_finalize(stmt);\n")
+	g.output.WriteString("    " + g.db.FinalizeSnippet("stmt"))
 	g.output.WriteString("    *count = n;\n")
 	g.output.WriteString("    return results;\n")
 	g.output.WriteString("}\n\n")
@@ -446,22 +520,25 @@ func (g *CGenerator) generateAll(s *ast.StructDecl, tableName string) {
 func (g *CGenerator) generateDelete(s *ast.StructDecl, tableName string) {
 	g.output.WriteString(fmt.Sprintf("int %s_delete(int64_t id) {\n", s.Name))
 
-	g.output.WriteString(fmt.Sprintf("    char *sql = \"DELETE FROM %s WHERE id = ?\";\n", tableName))
-	g.output.WriteString("    sqlite3_stmt *stmt;\n\n")
+	g.output.WriteString(fmt.Sprintf("    char *sql = \"DELETE FROM %s WHERE id = %s\";\n", tableName, g.db.Placeholder(1)))
+	g.output.WriteString("    " + g.db.StmtVarDecl("stmt", 1) + "\n")
 
-	g.output.WriteString("    int rc = sqlite3_prepare_v2(db, sql, -1, &stmt, NULL);\n")
-	g.output.WriteString("    if (rc != SQLITE_OK) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Failed to prepare statement: %s\\n\", sqlite3_errmsg(db));\n")
+	g.output.WriteString("    " + g.db.PrepareSnippet("stmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to prepare statement: %%s\\n\", %s);\n", g.db.ErrorExpr()))
 	g.output.WriteString("        return 0;\n")
 	g.output.WriteString("    }\n\n")
 
-	g.output.WriteString("    sqlite3_bind_int64(stmt, 1, id);\n\n")
+	if snippet := g.db.BindSnippet("stmt", 0, "int64_t", "id"); snippet != "" {
+		g.output.WriteString("    " + snippet + "\n\n")
+	}
 
-	g.output.WriteString("    rc = sqlite3_step(stmt);\n")
-	g.output.WriteString("    sqlite3_finalize(stmt);\n\n")
+	g.output.WriteString("    " + g.db.ExecSnippet("stmt", 1) + "\n")
+	g.output.WriteString("    " + g.db.FinalizeSnippet("stmt"))
+	g.output.WriteString("\n")
 
-	g.output.WriteString("    if (rc != SQLITE_DONE) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Failed to delete: %s\\n\", sqlite3_errmsg(db));\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to delete: %%s\\n\", %s);\n", g.db.ErrorExpr()))
 	g.output.WriteString("        return 0;\n")
 	g.output.WriteString("    }\n\n")
 
@@ -469,16 +546,74 @@ func (g *CGenerator) generateDelete(s *ast.StructDecl, tableName string) {
 	g.output.WriteString("}\n\n")
 }
 
+// generateUpdate emits {Struct}_update(id, field, field, ...), an UPDATE ...
+// SET ... WHERE id = ? over every non-auto-increment field, mirroring
+// generateCreate's INSERT shape. Returns the updated struct, or NULL if no
+// row with that id exists.
+func (g *CGenerator) generateUpdate(s *ast.StructDecl, tableName string) {
+	g.output.WriteString(fmt.Sprintf("%s* %s_update(int64_t id, ", s.Name, s.Name))
+
+	params := []string{}
+	nonAutoFields := []*ast.FieldDecl{}
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+		cType := g.mapType(field.Type)
+		params = append(params, fmt.Sprintf("%s %s", cType, field.Name))
+		nonAutoFields = append(nonAutoFields, field)
+	}
+	g.output.WriteString(strings.Join(params, ", "))
+	g.output.WriteString(") {\n")
+
+	g.output.WriteString("    char sql[1024];\n")
+	g.output.WriteString(fmt.Sprintf("    sprintf(sql, \"UPDATE %s SET ", tableName))
+	setClauses := []string{}
+	for i, field := range nonAutoFields {
+		setClauses = append(setClauses, fmt.Sprintf("%s = %s", field.Name, g.db.Placeholder(i+1)))
+	}
+	g.output.WriteString(strings.Join(setClauses, ", "))
+	g.output.WriteString(fmt.Sprintf(" WHERE id = %s\");\n\n", g.db.Placeholder(len(nonAutoFields)+1)))
+
+	g.output.WriteString("    " + g.db.StmtVarDecl("stmt", len(nonAutoFields)+1))
+	g.output.WriteString("    " + g.db.PrepareSnippet("stmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to prepare statement: %%s\\n\", %s);\n", g.db.ErrorExpr()))
+	g.output.WriteString("        return NULL;\n")
+	g.output.WriteString("    }\n\n")
+
+	for i, field := range nonAutoFields {
+		cType := g.mapType(field.Type)
+		if snippet := g.db.BindSnippet("stmt", i, cType, field.Name); snippet != "" {
+			g.output.WriteString("    " + snippet + "\n")
+		}
+	}
+	if snippet := g.db.BindSnippet("stmt", len(nonAutoFields), "int64_t", "id"); snippet != "" {
+		g.output.WriteString("    " + snippet + "\n")
+	}
+
+	g.output.WriteString("\n    " + g.db.ExecSnippet("stmt", len(nonAutoFields)+1) + "\n")
+	g.output.WriteString("    " + g.db.FinalizeSnippet("stmt"))
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to update: %%s\\n\", %s);\n", g.db.ErrorExpr()))
+	g.output.WriteString("        return NULL;\n")
+	g.output.WriteString("    }\n\n")
+
+	g.output.WriteString(fmt.Sprintf("    return %s_find(id);\n", s.Name))
+	g.output.WriteString("}\n\n")
+}
+
 func (g *CGenerator) generateMain() {
 	g.output.WriteString("int main(int argc, char *argv[]) {\n")
 	g.output.WriteString("    // Initialize database\n")
-	g.output.WriteString("    int rc = sqlite3_open(\"app.db\", &db);\n")
-	g.output.WriteString("    if (rc != SQLITE_OK) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Cannot open database: %s\\n\", sqlite3_errmsg(db));\n")
-	g.output.WriteString("        return 1;\n")
-	g.output.WriteString("    }\n")
+	g.output.WriteString("    " + g.db.OpenSnippet(g.dsn) + "\n")
 	g.output.WriteString("    printf(\"Database opened successfully\\n\\n\");\n\n")
 
+	g.generateHookRegistrations()
+
+	g.output.WriteString("    // Apply any pending schema migrations\n")
+	g.output.WriteString("    run_migrations();\n\n")
+
 	// Initialize tables
 	for _, s := range g.structs {
 		g.output.WriteString(fmt.Sprintf("    %s_init_table();\n", s.Name))
@@ -606,7 +741,7 @@ func (g *CGenerator) generateMain() {
 	g.output.WriteString("    printf(\"\\n===== Demo Complete =====\\n\");\n\n")
 
 	g.output.WriteString("    // Close database\n")
-	g.output.WriteString("    sqlite3_close(db);\n")
+	g.output.WriteString("    " + g.db.CloseSnippet())
 	g.output.WriteString("    return 0;\n")
 	g.output.WriteString("}\n")
 }
@@ -646,6 +781,12 @@ const char* html_footer =
 
 `)
 
+	// Generate the standalone edit-form page (its own top-level function,
+	// reached directly by /{table}/edit rather than folded into the list page).
+	if len(g.structs) > 0 {
+		g.generateEditFormHTML(g.structs[0])
+	}
+
 	// Generate page rendering function for the first page
 	if len(g.pages) > 0 {
 		page := g.pages[0]
@@ -734,8 +875,8 @@ func (g *CGenerator) generateDataListHTML(s *ast.StructDecl) {
 		}
 	}
 
-	// Delete action
-	g.output.WriteString(fmt.Sprintf("        offset += sprintf(html + offset, \"<td><a href='/%s/delete?id=%%lld' class='btn btn-sm btn-danger'>Delete</a></td>\", items[i]->id);\n", tableName))
+	// Edit/Delete actions
+	g.output.WriteString(fmt.Sprintf("        offset += sprintf(html + offset, \"<td><a href='/%s/edit?id=%%lld' class='btn btn-sm btn-secondary'>Edit</a> <a href='/%s/delete?id=%%lld' class='btn btn-sm btn-danger'>Delete</a></td>\", items[i]->id, items[i]->id);\n", tableName, tableName))
 	g.output.WriteString("        offset += sprintf(html + offset, \"</tr>\\n\");\n")
 	g.output.WriteString("    }\n\n")
 	g.output.WriteString("    offset += sprintf(html + offset, \"</tbody></table>\\n\");\n\n")
@@ -746,7 +887,14 @@ func (g *CGenerator) generateFormHTML(s *ast.StructDecl) {
 
 	g.output.WriteString("    // Form - Add new record\n")
 	g.output.WriteString("    offset += sprintf(html + offset, \"<h2 class='mt-5'>Add New Item</h2>\\n\");\n")
-	g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<form method='POST' action='/%s/create'>\\n\");\n", tableName))
+	if structHasFileField(s) {
+		g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<form method='POST' action='/%s/create' enctype='multipart/form-data'>\\n\");\n", tableName))
+	} else {
+		g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<form method='POST' action='/%s/create'>\\n\");\n", tableName))
+	}
+	if g.authEnabled {
+		g.output.WriteString("    offset += sprintf(html + offset, \"<input type='hidden' name='csrf_token' value='%s'>\\n\", zl_csrf_token);\n")
+	}
 
 	// Generate form fields
 	for _, field := range s.Fields {
@@ -771,7 +919,9 @@ func (g *CGenerator) generateFormHTML(s *ast.StructDecl) {
 		g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<div class='mb-3'>\\n\");\n"))
 		g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<label class='form-label'>%s</label>\\n\");\n", fieldLabel))
 
-		if cType == "char*" {
+		if isFileField(field) {
+			g.output.WriteString(fileFormInput(field))
+		} else if cType == "char*" {
 			// Check if description field for textarea
 			if field.Name == "description" {
 				g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<textarea name='%s' class='form-control' rows='3' required></textarea>\\n\");\n", field.Name))
@@ -866,7 +1016,35 @@ enum MHD_Result handle_request(void *cls, struct MHD_Connection *connection,
 		g.output.WriteString("            char fields[10][256];\n")
 		g.output.WriteString("            char values[10][256];\n")
 		g.output.WriteString("            int count;\n")
-		g.output.WriteString("            parse_form_data(upload_data, fields, values, &count);\n\n")
+		if structHasFileField(s) {
+			g.output.WriteString("            const char* content_type = MHD_lookup_connection_value(connection, MHD_HEADER_KIND, \"Content-Type\");\n")
+			g.output.WriteString("            if (content_type && strncmp(content_type, \"multipart/form-data\", 20) == 0) {\n")
+			g.output.WriteString("                const char* boundary = strstr(content_type, \"boundary=\");\n")
+			g.output.WriteString("                char boundary_buf[256] = \"\";\n")
+			g.output.WriteString("                if (boundary) strcpy(boundary_buf, boundary + 9);\n")
+			g.output.WriteString("                parse_multipart(boundary_buf, upload_data, *upload_data_size, fields, values, &count);\n")
+			g.output.WriteString("            } else {\n")
+			g.output.WriteString("                parse_form_data(upload_data, fields, values, &count);\n")
+			g.output.WriteString("            }\n\n")
+		} else {
+			g.output.WriteString("            parse_form_data(upload_data, fields, values, &count);\n\n")
+		}
+
+		if g.authEnabled {
+			g.output.WriteString("            // CSRF check\n")
+			g.output.WriteString("            int csrf_ok = 0;\n")
+			g.output.WriteString("            for (int i = 0; i < count; i++) {\n")
+			g.output.WriteString("                if (strcmp(fields[i], \"csrf_token\") == 0 && strcmp(values[i], zl_csrf_token) == 0) csrf_ok = 1;\n")
+			g.output.WriteString("            }\n")
+			g.output.WriteString("            if (!csrf_ok) {\n")
+			g.output.WriteString("                *upload_data_size = 0;\n")
+			g.output.WriteString("                const char* denied = \"<h1>403 Forbidden: bad CSRF token</h1>\";\n")
+			g.output.WriteString("                response = MHD_create_response_from_buffer(strlen(denied), (void*)denied, MHD_RESPMEM_PERSISTENT);\n")
+			g.output.WriteString("                ret = MHD_queue_response(connection, MHD_HTTP_FORBIDDEN, response);\n")
+			g.output.WriteString("                MHD_destroy_response(response);\n")
+			g.output.WriteString("                return ret;\n")
+			g.output.WriteString("            }\n\n")
+		}
 
 		// Extract form values and create record
 		g.output.WriteString("            // Extract form values\n")
@@ -944,10 +1122,38 @@ enum MHD_Result handle_request(void *cls, struct MHD_Connection *connection,
 		g.output.WriteString("        return ret;\n")
 		g.output.WriteString("    }\n\n")
 
+		// Handle /{table}/edit and /{table}/update
+		g.generateEditRoutes(s, tableName)
+
+		// Handle GET for full-text search over @searchable fields
+		g.generateSearchRoute(s, tableName)
+
+		// Handle the /api/{table} JSON REST surface for every struct, not
+		// just the one the HTML routes above are scoped to.
+		for _, apiStruct := range g.structs {
+			g.generateAPIRoutes(apiStruct, g.getTableName(apiStruct))
+		}
+
+		// /graphql: a single flexible query surface over every table
+		g.generateGraphQLRoute()
+
+		// @auth: /register, /login, /logout
+		g.generateAuthRoutes()
+
 		// Handle root path - show page
 		if len(g.pages) > 0 {
 			page := g.pages[0]
 			g.output.WriteString("    if (strcmp(url, \"/\") == 0 && strcmp(method, \"GET\") == 0) {\n")
+			if g.authEnabled && pageIsProtected(page) {
+				g.output.WriteString("        if (require_session(connection) < 0) {\n")
+				g.output.WriteString("            const char* redirect = \"<html><head><meta http-equiv='refresh' content='0;url=/login'></head></html>\";\n")
+				g.output.WriteString("            response = MHD_create_response_from_buffer(strlen(redirect), (void*)redirect, MHD_RESPMEM_PERSISTENT);\n")
+				g.output.WriteString("            ret = MHD_queue_response(connection, MHD_HTTP_SEE_OTHER, response);\n")
+				g.output.WriteString("            MHD_add_response_header(response, \"Location\", \"/login\");\n")
+				g.output.WriteString("            MHD_destroy_response(response);\n")
+				g.output.WriteString("            return ret;\n")
+				g.output.WriteString("        }\n")
+			}
 			g.output.WriteString(fmt.Sprintf("        char* html = render_%s_page();\n", strings.ToLower(page.Name)))
 			g.output.WriteString("        response = MHD_create_response_from_buffer(strlen(html), (void*)html, MHD_RESPMEM_MUST_FREE);\n")
 			g.output.WriteString("        MHD_add_response_header(response, \"Content-Type\", \"text/html\");\n")
@@ -967,42 +1173,6 @@ enum MHD_Result handle_request(void *cls, struct MHD_Connection *connection,
 	}
 }
 
-func (g *CGenerator) generateWebMain() {
-	g.output.WriteString("int main(int argc, char *argv[]) {\n")
-	g.output.WriteString("    // Initialize database\n")
-	g.output.WriteString("    int rc = sqlite3_open(\"app.db\", &db);\n")
-	g.output.WriteString("    if (rc != SQLITE_OK) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Cannot open database: %s\\n\", sqlite3_errmsg(db));\n")
-	g.output.WriteString("        return 1;\n")
-	g.output.WriteString("    }\n")
-	g.output.WriteString("    printf(\"Database opened successfully\\n\");\n\n")
-
-	// Initialize tables
-	for _, s := range g.structs {
-		g.output.WriteString(fmt.Sprintf("    %s_init_table();\n", s.Name))
-	}
-
-	g.output.WriteString("\n    // Start HTTP server\n")
-	g.output.WriteString("    http_daemon = MHD_start_daemon(MHD_USE_SELECT_INTERNALLY, 8080, NULL, NULL,\n")
-	g.output.WriteString("                                    &handle_request, NULL, MHD_OPTION_END);\n")
-	g.output.WriteString("    if (http_daemon == NULL) {\n")
-	g.output.WriteString("        fprintf(stderr, \"Failed to start HTTP server\\n\");\n")
-	g.output.WriteString("        return 1;\n")
-	g.output.WriteString("    }\n\n")
-
-	g.output.WriteString("    printf(\"\\n========================================\\n\");\n")
-	g.output.WriteString("    printf(\"Server running on http://localhost:8080\\n\");\n")
-	g.output.WriteString("    printf(\"Press ENTER to stop the server...\\n\");\n")
-	g.output.WriteString("    printf(\"========================================\\n\\n\");\n\n")
-
-	g.output.WriteString("    getchar();\n\n")
-
-	g.output.WriteString("    // Stop HTTP server\n")
-	g.output.WriteString("    MHD_stop_daemon(http_daemon);\n\n")
-
-	g.output.WriteString("    // Close database\n")
-	g.output.WriteString("    sqlite3_close(db);\n")
-	g.output.WriteString("    printf(\"Server stopped\\n\");\n")
-	g.output.WriteString("    return 0;\n")
-	g.output.WriteString("}\n")
-}
+// generateWebMain now lives in daemon.go, which replaces the blocking
+// getchar() this function used to end on with a real start/stop/restart/
+// status/foreground lifecycle.
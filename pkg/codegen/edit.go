@@ -0,0 +1,205 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/decorator"
+)
+
+// generateEditFormHTML emits render_{table}_edit_form(obj), a standalone HTML
+// page wrapping a <form action='/{table}/update?id=...'> pre-filled from an
+// already-fetched obj, mirroring generateFormHTML's field rendering but with
+// value=/checked attributes instead of empty inputs. It's a full page (not a
+// fragment folded into render_{page}_page) since /{table}/edit is reached by
+// its own URL, the same way /login is in auth.go.
+func (g *CGenerator) generateEditFormHTML(s *ast.StructDecl) {
+	tableName := g.getTableName(s)
+
+	g.output.WriteString(fmt.Sprintf("char* render_%s_edit_form(%s* obj) {\n", tableName, s.Name))
+	g.output.WriteString("    char* html = (char*)malloc(65536);\n")
+	g.output.WriteString("    int offset = 0;\n\n")
+	g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, html_header, \"Edit %s\");\n", s.Name))
+	g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<h1 class='mb-4'>Edit %s</h1>\\n\");\n", s.Name))
+
+	if structHasFileField(s) {
+		g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<form method='POST' action='/%s/update?id=%%lld' enctype='multipart/form-data'>\\n\", (long long)obj->id);\n", tableName))
+	} else {
+		g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<form method='POST' action='/%s/update?id=%%lld'>\\n\", (long long)obj->id);\n", tableName))
+	}
+	if g.authEnabled {
+		g.output.WriteString("    offset += sprintf(html + offset, \"<input type='hidden' name='csrf_token' value='%s'>\\n\", zl_csrf_token);\n")
+	}
+
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+
+		fieldLabel := strings.Title(field.Name)
+		cType := g.mapType(field.Type)
+
+		g.output.WriteString("    offset += sprintf(html + offset, \"<div class='mb-3'>\\n\");\n")
+		g.output.WriteString(fmt.Sprintf("    offset += sprintf(html + offset, \"<label class='form-label'>%s</label>\\n\");\n", fieldLabel))
+
+		switch {
+		case isFileField(field):
+			g.output.WriteString(fileFormInput(field))
+		case field.Type == "bool":
+			g.output.WriteString(fmt.Sprintf(
+				"    offset += sprintf(html + offset, \"<input type='checkbox' name='%s' class='form-check-input'%%s>\\n\", obj->%s ? \" checked\" : \"\");\n",
+				field.Name, field.Name))
+		case cType == "char*" && field.Name == "description":
+			g.output.WriteString(fmt.Sprintf(
+				"    offset += sprintf(html + offset, \"<textarea name='%s' class='form-control' rows='3' required>%%s</textarea>\\n\", obj->%s);\n",
+				field.Name, field.Name))
+		case cType == "char*":
+			g.output.WriteString(fmt.Sprintf(
+				"    offset += sprintf(html + offset, \"<input type='text' name='%s' class='form-control' value='%%s' required>\\n\", obj->%s);\n",
+				field.Name, field.Name))
+		case cType == "double":
+			g.output.WriteString(fmt.Sprintf(
+				"    offset += sprintf(html + offset, \"<input type='number' name='%s' class='form-control' value='%%f' required>\\n\", obj->%s);\n",
+				field.Name, field.Name))
+		default:
+			g.output.WriteString(fmt.Sprintf(
+				"    offset += sprintf(html + offset, \"<input type='number' name='%s' class='form-control' value='%%lld' required>\\n\", (long long)obj->%s);\n",
+				field.Name, field.Name))
+		}
+
+		g.output.WriteString("    offset += sprintf(html + offset, \"</div>\\n\");\n")
+	}
+
+	g.output.WriteString("    offset += sprintf(html + offset, \"<button type='submit' class='btn btn-primary'>Save</button>\\n\");\n")
+	g.output.WriteString("    offset += sprintf(html + offset, \"</form>\\n\");\n")
+	g.output.WriteString("    offset += sprintf(html + offset, \"%s\", html_footer);\n")
+	g.output.WriteString("    return html;\n")
+	g.output.WriteString("}\n\n")
+}
+
+// generateEditRoutes emits the /{table}/edit (GET) and /{table}/update
+// (POST) routes inside handle_request: edit renders render_{table}_edit_form
+// against {Struct}_find(id); update parses the form the same way the create
+// handler does (including the multipart switch for structs with a file
+// field) and calls {Struct}_update.
+func (g *CGenerator) generateEditRoutes(s *ast.StructDecl, tableName string) {
+	g.output.WriteString(fmt.Sprintf("    if (strcmp(url, \"/%s/edit\") == 0 && strcmp(method, \"GET\") == 0) {\n", tableName))
+	g.output.WriteString("        const char* id_str = MHD_lookup_connection_value(connection, MHD_GET_ARGUMENT_KIND, \"id\");\n")
+	g.output.WriteString("        if (!id_str) {\n")
+	g.output.WriteString("            const char* redirect = \"<html><head><meta http-equiv='refresh' content='0;url=/'></head></html>\";\n")
+	g.output.WriteString("            response = MHD_create_response_from_buffer(strlen(redirect), (void*)redirect, MHD_RESPMEM_PERSISTENT);\n")
+	g.output.WriteString("            ret = MHD_queue_response(connection, MHD_HTTP_SEE_OTHER, response);\n")
+	g.output.WriteString("            MHD_add_response_header(response, \"Location\", \"/\");\n")
+	g.output.WriteString("            MHD_destroy_response(response);\n")
+	g.output.WriteString("            return ret;\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString(fmt.Sprintf("        %s* obj = %s_find(atoll(id_str));\n", s.Name, s.Name))
+	g.output.WriteString("        if (!obj) {\n")
+	g.output.WriteString("            response = MHD_create_response_from_buffer(9, (void*)\"Not Found\", MHD_RESPMEM_PERSISTENT);\n")
+	g.output.WriteString("            ret = MHD_queue_response(connection, MHD_HTTP_NOT_FOUND, response);\n")
+	g.output.WriteString("            MHD_destroy_response(response);\n")
+	g.output.WriteString("            return ret;\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString(fmt.Sprintf("        char* form = render_%s_edit_form(obj);\n", tableName))
+	g.output.WriteString("        response = MHD_create_response_from_buffer(strlen(form), (void*)form, MHD_RESPMEM_MUST_FREE);\n")
+	g.output.WriteString("        MHD_add_response_header(response, \"Content-Type\", \"text/html\");\n")
+	g.output.WriteString("        ret = MHD_queue_response(connection, MHD_HTTP_OK, response);\n")
+	g.output.WriteString("        MHD_destroy_response(response);\n")
+	g.output.WriteString("        return ret;\n")
+	g.output.WriteString("    }\n\n")
+
+	g.output.WriteString(fmt.Sprintf("    if (strcmp(url, \"/%s/update\") == 0 && strcmp(method, \"POST\") == 0) {\n", tableName))
+	g.output.WriteString("        if (*con_cls == NULL) {\n")
+	g.output.WriteString("            *con_cls = (void*)1;\n")
+	g.output.WriteString("            return MHD_YES;\n")
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        if (*upload_data_size != 0) {\n")
+	g.output.WriteString("            char fields[10][256];\n")
+	g.output.WriteString("            char values[10][256];\n")
+	g.output.WriteString("            int count;\n")
+	if structHasFileField(s) {
+		g.output.WriteString("            const char* content_type = MHD_lookup_connection_value(connection, MHD_HEADER_KIND, \"Content-Type\");\n")
+		g.output.WriteString("            if (content_type && strncmp(content_type, \"multipart/form-data\", 20) == 0) {\n")
+		g.output.WriteString("                const char* boundary = strstr(content_type, \"boundary=\");\n")
+		g.output.WriteString("                char boundary_buf[256] = \"\";\n")
+		g.output.WriteString("                if (boundary) strcpy(boundary_buf, boundary + 9);\n")
+		g.output.WriteString("                parse_multipart(boundary_buf, upload_data, *upload_data_size, fields, values, &count);\n")
+		g.output.WriteString("            } else {\n")
+		g.output.WriteString("                parse_form_data(upload_data, fields, values, &count);\n")
+		g.output.WriteString("            }\n\n")
+	} else {
+		g.output.WriteString("            parse_form_data(upload_data, fields, values, &count);\n\n")
+	}
+
+	if g.authEnabled {
+		g.output.WriteString("            int csrf_ok = 0;\n")
+		g.output.WriteString("            for (int i = 0; i < count; i++) {\n")
+		g.output.WriteString("                if (strcmp(fields[i], \"csrf_token\") == 0 && strcmp(values[i], zl_csrf_token) == 0) csrf_ok = 1;\n")
+		g.output.WriteString("            }\n")
+		g.output.WriteString("            if (!csrf_ok) {\n")
+		g.output.WriteString("                *upload_data_size = 0;\n")
+		g.output.WriteString("                const char* denied = \"<h1>403 Forbidden: bad CSRF token</h1>\";\n")
+		g.output.WriteString("                response = MHD_create_response_from_buffer(strlen(denied), (void*)denied, MHD_RESPMEM_PERSISTENT);\n")
+		g.output.WriteString("                ret = MHD_queue_response(connection, MHD_HTTP_FORBIDDEN, response);\n")
+		g.output.WriteString("                MHD_destroy_response(response);\n")
+		g.output.WriteString("                return ret;\n")
+		g.output.WriteString("            }\n\n")
+	}
+
+	g.output.WriteString("            const char* id_str = MHD_lookup_connection_value(connection, MHD_GET_ARGUMENT_KIND, \"id\");\n")
+	g.output.WriteString("            int64_t id = id_str ? atoll(id_str) : 0;\n\n")
+
+	nonAutoFields := []*ast.FieldDecl{}
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+		nonAutoFields = append(nonAutoFields, field)
+		cType := g.mapType(field.Type)
+		if cType == "char*" {
+			g.output.WriteString(fmt.Sprintf("            char* %s = \"\";\n", field.Name))
+		} else if field.Type == "bool" {
+			g.output.WriteString(fmt.Sprintf("            int %s = 0;\n", field.Name))
+		} else {
+			g.output.WriteString(fmt.Sprintf("            int64_t %s = 0;\n", field.Name))
+		}
+	}
+
+	g.output.WriteString("            for (int i = 0; i < count; i++) {\n")
+	for _, field := range nonAutoFields {
+		cType := g.mapType(field.Type)
+		if cType == "char*" {
+			g.output.WriteString(fmt.Sprintf("                if (strcmp(fields[i], \"%s\") == 0) %s = strdup(values[i]);\n",
+				field.Name, field.Name))
+		} else if field.Type == "bool" {
+			g.output.WriteString(fmt.Sprintf("                if (strcmp(fields[i], \"%s\") == 0) %s = 1;\n",
+				field.Name, field.Name))
+		} else {
+			g.output.WriteString(fmt.Sprintf("                if (strcmp(fields[i], \"%s\") == 0) %s = atoll(values[i]);\n",
+				field.Name, field.Name))
+		}
+	}
+	g.output.WriteString("            }\n\n")
+
+	g.output.WriteString(fmt.Sprintf("            %s_update(id, ", s.Name))
+	args := []string{}
+	for _, field := range nonAutoFields {
+		args = append(args, field.Name)
+	}
+	g.output.WriteString(strings.Join(args, ", "))
+	g.output.WriteString(");\n\n")
+
+	g.output.WriteString("            *upload_data_size = 0;\n")
+	g.output.WriteString("            return MHD_YES;\n")
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        const char* redirect = \"<html><head><meta http-equiv='refresh' content='0;url=/'></head></html>\";\n")
+	g.output.WriteString("        response = MHD_create_response_from_buffer(strlen(redirect), (void*)redirect, MHD_RESPMEM_PERSISTENT);\n")
+	g.output.WriteString("        ret = MHD_queue_response(connection, MHD_HTTP_SEE_OTHER, response);\n")
+	g.output.WriteString("        MHD_add_response_header(response, \"Location\", \"/\");\n")
+	g.output.WriteString("        MHD_destroy_response(response);\n")
+	g.output.WriteString("        return ret;\n")
+	g.output.WriteString("    }\n\n")
+}
@@ -0,0 +1,381 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/decorator"
+)
+
+// @storage("fs", "./data") switches a struct off the SQL backend entirely:
+// each record becomes a JSON file under <path>/<table>/<id>.json, with a
+// monotonic id kept in <path>/<table>/.next_id. There is no SQL involved, so
+// (unlike FTS or hooks) this doesn't sit behind g.db.Name() - it's an
+// alternative to g.db, not a dialect-specific extra. generateCRUD branches
+// to this file entirely for a struct carrying @storage("fs", ...); the
+// query builder and @searchable/FTS index are SQL-only and simply don't
+// apply to such a struct.
+
+// fsStorageDir reports the data directory for s's @storage("fs", ...)
+// decorator, and whether s uses fs storage at all. "./data" is the default
+// when no path argument is given.
+func fsStorageDir(s *ast.StructDecl) (string, bool) {
+	backend, path, ok := decorator.Storage(s.Decorators)
+	if !ok || backend != "fs" {
+		return "", false
+	}
+	if path == "" {
+		path = "./data"
+	}
+	return path, true
+}
+
+// fsCacheSize bounds the per-struct in-process LRU cache generateFSCRUD
+// emits, so _all() and _find() on a previously-seen id skip re-reading and
+// re-parsing its file.
+const fsCacheSize = 16
+
+// generateFSRuntime emits the JSON read/write helpers shared by every
+// struct using fs storage: a tiny hand-rolled writer/parser for the
+// known-shape objects generateFSCreate/generateFSFind themselves emit (no
+// external JSON dependency), plus the monotonic id counter. No-op if no
+// struct uses fs storage.
+func (g *CGenerator) generateFSRuntime() {
+	if !g.usesFSStorage() {
+		return
+	}
+
+	g.output.WriteString("// File-backed storage for @storage(\"fs\", ...) structs: one JSON file per\n")
+	g.output.WriteString("// record, hand-rolled reader/writer for the fixed shape this generator itself emits.\n")
+	g.output.WriteString(`static char* zl_fs_read_file(const char *path) {
+    FILE *f = fopen(path, "r");
+    if (!f) return NULL;
+    fseek(f, 0, SEEK_END);
+    long size = ftell(f);
+    fseek(f, 0, SEEK_SET);
+    char *buf = (char*)malloc(size + 1);
+    size_t n = fread(buf, 1, size, f);
+    buf[n] = '\0';
+    fclose(f);
+    return buf;
+}
+
+static long long zl_fs_extract_int(const char *json, const char *key) {
+    char needle[256];
+    snprintf(needle, sizeof(needle), "\"%s\":", key);
+    const char *p = strstr(json, needle);
+    if (!p) return 0;
+    p += strlen(needle);
+    return strtoll(p, NULL, 10);
+}
+
+static double zl_fs_extract_float(const char *json, const char *key) {
+    char needle[256];
+    snprintf(needle, sizeof(needle), "\"%s\":", key);
+    const char *p = strstr(json, needle);
+    if (!p) return 0.0;
+    p += strlen(needle);
+    return strtod(p, NULL);
+}
+
+static char* zl_fs_extract_string(const char *json, const char *key) {
+    char needle[256];
+    snprintf(needle, sizeof(needle), "\"%s\": \"", key);
+    const char *p = strstr(json, needle);
+    if (!p) return strdup("");
+    p += strlen(needle);
+    const char *end = strchr(p, '"');
+    if (!end) return strdup("");
+    size_t len = end - p;
+    char *out = (char*)malloc(len + 1);
+    memcpy(out, p, len);
+    out[len] = '\0';
+    return out;
+}
+
+static void zl_fs_write_string(FILE *f, const char *value) {
+    for (const char *c = value; *c; c++) {
+        if (*c == '"' || *c == '\\') fputc('\\', f);
+        fputc(*c, f);
+    }
+}
+
+static void zl_fs_mkdir_p(const char *path) {
+    mkdir(path, 0755);
+}
+
+static long long zl_fs_next_id(const char *dir) {
+    char path[512];
+    snprintf(path, sizeof(path), "%s/.next_id", dir);
+    long long id = 1;
+    char *cur = zl_fs_read_file(path);
+    if (cur) {
+        id = strtoll(cur, NULL, 10);
+        free(cur);
+    }
+    FILE *f = fopen(path, "w");
+    if (f) {
+        fprintf(f, "%lld", id + 1);
+        fclose(f);
+    }
+    return id;
+}
+
+`)
+}
+
+// usesFSStorage reports whether any struct in the program uses fs storage.
+func (g *CGenerator) usesFSStorage() bool {
+	for _, s := range g.structs {
+		if _, ok := fsStorageDir(s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlStructs returns g.structs minus any carrying @storage("fs", ...), i.e.
+// the structs that actually have a SQL table for the migration pipeline to
+// diff against.
+func (g *CGenerator) sqlStructs() []*ast.StructDecl {
+	var out []*ast.StructDecl
+	for _, s := range g.structs {
+		if _, ok := fsStorageDir(s); !ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// generateFSCache emits s's fixed-capacity, recency-ordered record cache:
+// _fs_cache_get/_put/_invalidate, shared by _find/_all/_create/_delete so a
+// file already read once this run isn't re-parsed.
+func (g *CGenerator) generateFSCache(s *ast.StructDecl) {
+	name := s.Name
+	g.output.WriteString(fmt.Sprintf("typedef struct { int64_t id; %s *item; int64_t lru; } %s_fs_cache_entry;\n", name, name))
+	g.output.WriteString(fmt.Sprintf("static %s_fs_cache_entry %s_fs_cache[%d];\n", name, name, fsCacheSize))
+	g.output.WriteString(fmt.Sprintf("static int64_t %s_fs_cache_clock = 0;\n\n", name))
+
+	g.output.WriteString(fmt.Sprintf("static %s* %s_fs_cache_get(int64_t id) {\n", name, name))
+	g.output.WriteString(fmt.Sprintf("    for (int i = 0; i < %d; i++) {\n", fsCacheSize))
+	g.output.WriteString(fmt.Sprintf("        if (%s_fs_cache[i].item && %s_fs_cache[i].id == id) {\n", name, name))
+	g.output.WriteString(fmt.Sprintf("            %s_fs_cache[i].lru = ++%s_fs_cache_clock;\n", name, name))
+	g.output.WriteString(fmt.Sprintf("            return %s_fs_cache[i].item;\n", name))
+	g.output.WriteString("        }\n    }\n    return NULL;\n}\n\n")
+
+	g.output.WriteString(fmt.Sprintf("static void %s_fs_cache_put(int64_t id, %s *item) {\n", name, name))
+	g.output.WriteString("    int slot = 0;\n")
+	g.output.WriteString(fmt.Sprintf("    int64_t oldest = %s_fs_cache[0].lru;\n", name))
+	g.output.WriteString(fmt.Sprintf("    for (int i = 0; i < %d; i++) {\n", fsCacheSize))
+	g.output.WriteString(fmt.Sprintf("        if (!%s_fs_cache[i].item) { slot = i; oldest = -1; break; }\n", name))
+	g.output.WriteString(fmt.Sprintf("        if (%s_fs_cache[i].lru < oldest) { oldest = %s_fs_cache[i].lru; slot = i; }\n", name, name))
+	g.output.WriteString("    }\n")
+	g.output.WriteString(fmt.Sprintf("    %s_fs_cache[slot].id = id;\n", name))
+	g.output.WriteString(fmt.Sprintf("    %s_fs_cache[slot].item = item;\n", name))
+	g.output.WriteString(fmt.Sprintf("    %s_fs_cache[slot].lru = ++%s_fs_cache_clock;\n", name, name))
+	g.output.WriteString("}\n\n")
+
+	g.output.WriteString(fmt.Sprintf("static void %s_fs_cache_invalidate(int64_t id) {\n", name))
+	g.output.WriteString(fmt.Sprintf("    for (int i = 0; i < %d; i++) {\n", fsCacheSize))
+	g.output.WriteString(fmt.Sprintf("        if (%s_fs_cache[i].item && %s_fs_cache[i].id == id) %s_fs_cache[i].item = NULL;\n", name, name, name))
+	g.output.WriteString("    }\n}\n\n")
+}
+
+// fieldExtractExpr returns the C expression that parses field out of a
+// buffer named jsonVar, per generateFSWriteRecord's fixed output shape.
+func fieldExtractExpr(field *ast.FieldDecl, jsonVar string) string {
+	switch field.Type {
+	case "int", "bool":
+		return fmt.Sprintf("zl_fs_extract_int(%s, \"%s\")", jsonVar, field.Name)
+	case "float":
+		return fmt.Sprintf("zl_fs_extract_float(%s, \"%s\")", jsonVar, field.Name)
+	default:
+		return fmt.Sprintf("zl_fs_extract_string(%s, \"%s\")", jsonVar, field.Name)
+	}
+}
+
+// generateFSWriteRecord emits the body of a "write obj to <dir>/<id>.json"
+// block; objVar is the in-scope struct pointer and idExpr the id to name the
+// file after.
+func (g *CGenerator) generateFSWriteRecord(s *ast.StructDecl, dir, objVar, idExpr string) {
+	fields := nonArrayFields(s)
+	g.output.WriteString("    {\n")
+	g.output.WriteString("        char path[512];\n")
+	g.output.WriteString(fmt.Sprintf("        snprintf(path, sizeof(path), \"%s/%%lld.json\", (long long)%s);\n", dir, idExpr))
+	g.output.WriteString("        FILE *f = fopen(path, \"w\");\n")
+	g.output.WriteString("        if (f) {\n")
+	g.output.WriteString("            fprintf(f, \"{\\n\");\n")
+	for i, field := range fields {
+		comma := ","
+		if i == len(fields)-1 {
+			comma = ""
+		}
+		switch field.Type {
+		case "int", "bool":
+			g.output.WriteString(fmt.Sprintf("            fprintf(f, \"  \\\"%s\\\": %%lld%s\\n\", (long long)%s->%s);\n", field.Name, comma, objVar, field.Name))
+		case "float":
+			g.output.WriteString(fmt.Sprintf("            fprintf(f, \"  \\\"%s\\\": %%f%s\\n\", %s->%s);\n", field.Name, comma, objVar, field.Name))
+		default:
+			g.output.WriteString(fmt.Sprintf("            fprintf(f, \"  \\\"%s\\\": \\\"\");\n", field.Name))
+			g.output.WriteString(fmt.Sprintf("            zl_fs_write_string(f, %s->%s);\n", objVar, field.Name))
+			g.output.WriteString(fmt.Sprintf("            fprintf(f, \"\\\"%s\\n\");\n", comma))
+		}
+	}
+	g.output.WriteString("            fprintf(f, \"}\\n\");\n")
+	g.output.WriteString("            fclose(f);\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString("    }\n")
+}
+
+// generateFSCRUD replaces generateCRUD's SQL-backed emitters for a struct
+// carrying @storage("fs", path): _init_table ensures the data directory
+// exists, _create/_find/_all/_delete operate on one JSON file per record.
+// The chainable query builder and @searchable full-text search are SQL-only
+// features and don't apply here.
+func (g *CGenerator) generateFSCRUD(s *ast.StructDecl, tableName, path string) {
+	dir := fmt.Sprintf("%s/%s", strings.TrimSuffix(path, "/"), tableName)
+	name := s.Name
+
+	g.generateFSCache(s)
+
+	g.output.WriteString(fmt.Sprintf("void %s_init_table() {\n", name))
+	g.output.WriteString(fmt.Sprintf("    zl_fs_mkdir_p(\"%s\");\n", path))
+	g.output.WriteString(fmt.Sprintf("    zl_fs_mkdir_p(\"%s\");\n", dir))
+	g.output.WriteString(fmt.Sprintf("    printf(\"Data directory %s ready\\n\");\n", dir))
+	g.output.WriteString("}\n\n")
+
+	g.generateFSCreate(s, dir)
+	g.generateFSFind(s, dir)
+	g.generateFSAll(s, dir)
+	g.generateFSDelete(s, dir)
+	g.generateFSUpdate(s, dir)
+}
+
+func (g *CGenerator) generateFSCreate(s *ast.StructDecl, dir string) {
+	name := s.Name
+	g.output.WriteString(fmt.Sprintf("%s* %s_create(", name, name))
+
+	params := []string{}
+	nonAutoFields := []*ast.FieldDecl{}
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+		params = append(params, fmt.Sprintf("%s %s", g.mapType(field.Type), field.Name))
+		nonAutoFields = append(nonAutoFields, field)
+	}
+	g.output.WriteString(strings.Join(params, ", "))
+	g.output.WriteString(") {\n")
+
+	g.output.WriteString(fmt.Sprintf("    int64_t new_id = zl_fs_next_id(\"%s\");\n", dir))
+	g.output.WriteString(fmt.Sprintf("    %s* obj = (%s*)malloc(sizeof(%s));\n", name, name, name))
+	g.output.WriteString("    obj->id = new_id;\n")
+	for _, field := range nonAutoFields {
+		if field.Name == "id" {
+			continue
+		}
+		if g.mapType(field.Type) == "char*" {
+			g.output.WriteString(fmt.Sprintf("    obj->%s = strdup(%s);\n", field.Name, field.Name))
+		} else {
+			g.output.WriteString(fmt.Sprintf("    obj->%s = %s;\n", field.Name, field.Name))
+		}
+	}
+	g.output.WriteString("\n")
+	g.generateFSWriteRecord(s, dir, "obj", "new_id")
+	g.output.WriteString(fmt.Sprintf("    %s_fs_cache_put(new_id, obj);\n", name))
+	g.output.WriteString("\n    return obj;\n")
+	g.output.WriteString("}\n\n")
+}
+
+func (g *CGenerator) generateFSFind(s *ast.StructDecl, dir string) {
+	name := s.Name
+	g.output.WriteString(fmt.Sprintf("%s* %s_find(int64_t id) {\n", name, name))
+	g.output.WriteString(fmt.Sprintf("    %s* cached = %s_fs_cache_get(id);\n", name, name))
+	g.output.WriteString("    if (cached) return cached;\n\n")
+	g.output.WriteString("    char path[512];\n")
+	g.output.WriteString(fmt.Sprintf("    snprintf(path, sizeof(path), \"%s/%%lld.json\", (long long)id);\n", dir))
+	g.output.WriteString("    char *json = zl_fs_read_file(path);\n")
+	g.output.WriteString("    if (!json) return NULL;\n\n")
+	g.output.WriteString(fmt.Sprintf("    %s* obj = (%s*)malloc(sizeof(%s));\n", name, name, name))
+	for _, field := range nonArrayFields(s) {
+		if field.Name == "id" {
+			g.output.WriteString("    obj->id = id;\n")
+			continue
+		}
+		g.output.WriteString(fmt.Sprintf("    obj->%s = %s;\n", field.Name, fieldExtractExpr(field, "json")))
+	}
+	g.output.WriteString("    free(json);\n")
+	g.output.WriteString(fmt.Sprintf("    %s_fs_cache_put(id, obj);\n", name))
+	g.output.WriteString("    return obj;\n")
+	g.output.WriteString("}\n\n")
+}
+
+func (g *CGenerator) generateFSAll(s *ast.StructDecl, dir string) {
+	name := s.Name
+	g.output.WriteString(fmt.Sprintf("%s** %s_all(int* count) {\n", name, name))
+	g.output.WriteString(fmt.Sprintf("    DIR *d = opendir(\"%s\");\n", dir))
+	g.output.WriteString("    if (!d) {\n        *count = 0;\n        return NULL;\n    }\n\n")
+	g.output.WriteString("    int capacity = 10;\n")
+	g.output.WriteString(fmt.Sprintf("    %s** results = (%s**)malloc(capacity * sizeof(%s*));\n", name, name, name))
+	g.output.WriteString("    int n = 0;\n")
+	g.output.WriteString("    struct dirent *entry;\n")
+	g.output.WriteString("    while ((entry = readdir(d)) != NULL) {\n")
+	g.output.WriteString("        long long id = strtoll(entry->d_name, NULL, 10);\n")
+	g.output.WriteString("        if (id <= 0) continue;\n")
+	g.output.WriteString("        if (n >= capacity) {\n")
+	g.output.WriteString("            capacity *= 2;\n")
+	g.output.WriteString(fmt.Sprintf("            results = (%s**)realloc(results, capacity * sizeof(%s*));\n", name, name))
+	g.output.WriteString("        }\n")
+	g.output.WriteString(fmt.Sprintf("        results[n++] = %s_find(id);\n", name))
+	g.output.WriteString("    }\n")
+	g.output.WriteString("    closedir(d);\n\n")
+	g.output.WriteString("    *count = n;\n")
+	g.output.WriteString("    return results;\n")
+	g.output.WriteString("}\n\n")
+}
+
+// generateFSUpdate emits {Struct}_update(id, field, field, ...), rewriting
+// the record's JSON file in place and refreshing its cache entry. Returns
+// NULL if no file with that id exists.
+func (g *CGenerator) generateFSUpdate(s *ast.StructDecl, dir string) {
+	name := s.Name
+	params := []string{}
+	nonAutoFields := []*ast.FieldDecl{}
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+		params = append(params, fmt.Sprintf("%s %s", g.mapType(field.Type), field.Name))
+		nonAutoFields = append(nonAutoFields, field)
+	}
+
+	g.output.WriteString(fmt.Sprintf("%s* %s_update(int64_t id, %s) {\n", name, name, strings.Join(params, ", ")))
+	g.output.WriteString(fmt.Sprintf("    %s* obj = %s_find(id);\n", name, name))
+	g.output.WriteString("    if (!obj) return NULL;\n\n")
+	for _, field := range nonAutoFields {
+		if field.Name == "id" {
+			continue
+		}
+		if g.mapType(field.Type) == "char*" {
+			g.output.WriteString(fmt.Sprintf("    obj->%s = strdup(%s);\n", field.Name, field.Name))
+		} else {
+			g.output.WriteString(fmt.Sprintf("    obj->%s = %s;\n", field.Name, field.Name))
+		}
+	}
+	g.output.WriteString("\n")
+	g.generateFSWriteRecord(s, dir, "obj", "id")
+	g.output.WriteString(fmt.Sprintf("    %s_fs_cache_put(id, obj);\n", name))
+	g.output.WriteString("    return obj;\n")
+	g.output.WriteString("}\n\n")
+}
+
+func (g *CGenerator) generateFSDelete(s *ast.StructDecl, dir string) {
+	name := s.Name
+	g.output.WriteString(fmt.Sprintf("int %s_delete(int64_t id) {\n", name))
+	g.output.WriteString("    char path[512];\n")
+	g.output.WriteString(fmt.Sprintf("    snprintf(path, sizeof(path), \"%s/%%lld.json\", (long long)id);\n", dir))
+	g.output.WriteString(fmt.Sprintf("    %s_fs_cache_invalidate(id);\n", name))
+	g.output.WriteString("    return unlink(path) == 0;\n")
+	g.output.WriteString("}\n\n")
+}
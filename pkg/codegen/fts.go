@@ -0,0 +1,174 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/decorator"
+)
+
+// Full-text search is backed by SQLite's FTS5 extension (the dialect
+// mattn/go-sqlite3 enables via its SQLITE_ENABLE_FTS5 build tag), so every
+// function here is a no-op unless g.db is the sqlite backend - there is no
+// MySQL/Postgres equivalent to fall back to.
+
+// searchableFields returns s's string fields carrying @searchable, in
+// declaration order.
+func searchableFields(s *ast.StructDecl) []*ast.FieldDecl {
+	var fields []*ast.FieldDecl
+	for _, f := range nonArrayFields(s) {
+		if f.Type == "string" && decorator.IsSearchable(f.Decorators) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// ftsTableName is the external-content FTS5 virtual table (content=tableName)
+// backing tableName's @searchable columns.
+func ftsTableName(tableName string) string { return tableName + "_fts" }
+
+// generateFTSIndex emits the FTS5 virtual table and the AFTER INSERT/UPDATE/
+// DELETE triggers that keep it in sync with tableName, for s's @searchable
+// fields. No-op when s has none, or the target dialect isn't sqlite.
+func (g *CGenerator) generateFTSIndex(s *ast.StructDecl, tableName string) {
+	fields := searchableFields(s)
+	if len(fields) == 0 || g.db.Name() != "sqlite" {
+		return
+	}
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	cols := strings.Join(names, ", ")
+	fts := ftsTableName(tableName)
+
+	newCols := make([]string, len(fields))
+	oldCols := make([]string, len(fields))
+	for i, name := range names {
+		newCols[i] = "new." + name
+		oldCols[i] = "old." + name
+	}
+
+	ddl := fmt.Sprintf(
+		"CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content='%s', content_rowid='id');\n"+
+			"CREATE TRIGGER IF NOT EXISTS %s_ai AFTER INSERT ON %s BEGIN\n"+
+			"  INSERT INTO %s(rowid, %s) VALUES (new.id, %s);\n"+
+			"END;\n"+
+			"CREATE TRIGGER IF NOT EXISTS %s_ad AFTER DELETE ON %s BEGIN\n"+
+			"  INSERT INTO %s(%s, rowid, %s) VALUES('delete', old.id, %s);\n"+
+			"END;\n"+
+			"CREATE TRIGGER IF NOT EXISTS %s_au AFTER UPDATE ON %s BEGIN\n"+
+			"  INSERT INTO %s(%s, rowid, %s) VALUES('delete', old.id, %s);\n"+
+			"  INSERT INTO %s(rowid, %s) VALUES (new.id, %s);\n"+
+			"END;",
+		fts, cols, tableName,
+		fts, tableName, fts, cols, strings.Join(newCols, ", "),
+		fts, tableName, fts, fts, cols, strings.Join(oldCols, ", "),
+		fts, tableName, fts, fts, cols, strings.Join(oldCols, ", "), fts, cols, strings.Join(newCols, ", "),
+	)
+
+	escaped := strings.ReplaceAll(ddl, `"`, `\"`)
+	escaped = strings.ReplaceAll(escaped, "\n", "\\n\"\n        \"")
+	g.output.WriteString("    {\n")
+	g.output.WriteString(fmt.Sprintf("    char *fts_sql = \"%s\";\n", escaped))
+	g.output.WriteString("    " + g.db.ExecDDLSnippet("fts_sql") + "\n")
+	g.output.WriteString(fmt.Sprintf("    if (rc != 0) fprintf(stderr, \"Failed to create FTS index for %s\\n\");\n", tableName))
+	g.output.WriteString("    }\n")
+}
+
+// generateSearch emits <Struct>_search(const char* query, int* count),
+// matching query against s's FTS5 index and hydrating full structs by
+// joining each matched rowid back to tableName via <Struct>_find. No-op
+// when s has no @searchable fields, or the target dialect isn't sqlite.
+func (g *CGenerator) generateSearch(s *ast.StructDecl, tableName string) {
+	if len(searchableFields(s)) == 0 || g.db.Name() != "sqlite" {
+		return
+	}
+	fts := ftsTableName(tableName)
+
+	g.output.WriteString(fmt.Sprintf("%s** %s_search(const char* query, int* count) {\n", s.Name, s.Name))
+	g.output.WriteString(fmt.Sprintf("    char *sql = \"SELECT rowid FROM %s WHERE %s MATCH ? ORDER BY rank\";\n", fts, fts))
+	g.output.WriteString("    " + g.db.StmtVarDecl("stmt", 1) + "\n")
+	g.output.WriteString("    " + g.db.PrepareSnippet("stmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString("        *count = 0;\n")
+	g.output.WriteString("        return NULL;\n")
+	g.output.WriteString("    }\n\n")
+
+	if snippet := g.db.BindSnippet("stmt", 0, "char*", "query"); snippet != "" {
+		g.output.WriteString("    " + snippet + "\n\n")
+	}
+
+	g.output.WriteString("    int capacity = 10;\n")
+	g.output.WriteString(fmt.Sprintf("    %s** results = (%s**)malloc(capacity * sizeof(%s*));\n", s.Name, s.Name, s.Name))
+	g.output.WriteString("    int n = 0;\n\n")
+
+	g.output.WriteString("    " + g.db.StepRowSnippet("stmt") + "\n")
+	g.output.WriteString("    while (rc == 1) {\n")
+	g.output.WriteString("        if (n >= capacity) {\n")
+	g.output.WriteString("            capacity *= 2;\n")
+	g.output.WriteString(fmt.Sprintf("            results = (%s**)realloc(results, capacity * sizeof(%s*));\n", s.Name, s.Name))
+	g.output.WriteString("        }\n")
+	g.output.WriteString("        int64_t matched_id = sqlite3_column_int64(stmt, 0);\n")
+	g.output.WriteString(fmt.Sprintf("        results[n++] = %s_find(matched_id);\n", s.Name))
+	g.output.WriteString("        " + g.db.StepRowSnippet("stmt") + "\n")
+	g.output.WriteString("    }\n\n")
+
+	g.output.WriteString("    " + g.db.FinalizeSnippet("stmt"))
+	g.output.WriteString("    *count = n;\n")
+	g.output.WriteString("    return results;\n")
+	g.output.WriteString("}\n\n")
+}
+
+// generateSearchRoute emits the /search HTTP route for s, rendering matches
+// with Bootstrap <mark> highlighting via FTS5's highlight() function. No-op
+// when s has no @searchable fields, or the target dialect isn't sqlite.
+func (g *CGenerator) generateSearchRoute(s *ast.StructDecl, tableName string) {
+	fields := searchableFields(s)
+	if len(fields) == 0 || g.db.Name() != "sqlite" {
+		return
+	}
+	fts := ftsTableName(tableName)
+
+	highlights := make([]string, len(fields))
+	for i := range fields {
+		highlights[i] = fmt.Sprintf("highlight(%s, %d, '<mark>', '</mark>')", fts, i)
+	}
+
+	g.output.WriteString("    if (strncmp(url, \"/search\", 7) == 0 && strcmp(method, \"GET\") == 0) {\n")
+	g.output.WriteString("        const char* q = MHD_lookup_connection_value(connection, MHD_GET_ARGUMENT_KIND, \"q\");\n")
+	g.output.WriteString("        char* html = (char*)malloc(65536);\n")
+	g.output.WriteString("        int offset = 0;\n")
+	g.output.WriteString("        offset += sprintf(html + offset, html_header, \"Search Results\");\n")
+	g.output.WriteString("        offset += sprintf(html + offset, \"<h1 class='mb-4'>Search Results</h1>\\n\");\n\n")
+	g.output.WriteString("        if (q && *q) {\n")
+	g.output.WriteString("            char sql[512];\n")
+	g.output.WriteString(fmt.Sprintf("            sprintf(sql, \"SELECT %s FROM %s WHERE %s MATCH ?\");\n",
+		strings.Join(highlights, ", "), fts, fts))
+	g.output.WriteString("            sqlite3_stmt *stmt;\n")
+	g.output.WriteString("            rc = sqlite3_prepare_v2(db, sql, -1, &stmt, NULL);\n")
+	g.output.WriteString("            if (rc == SQLITE_OK) {\n")
+	g.output.WriteString("                sqlite3_bind_text(stmt, 1, q, -1, SQLITE_TRANSIENT);\n")
+	g.output.WriteString("                offset += sprintf(html + offset, \"<ul class='list-group'>\\n\");\n")
+	g.output.WriteString("                while (sqlite3_step(stmt) == SQLITE_ROW) {\n")
+	g.output.WriteString("                    offset += sprintf(html + offset, \"<li class='list-group-item'>\");\n")
+	for i := range fields {
+		g.output.WriteString(fmt.Sprintf("                    offset += sprintf(html + offset, \"%%s \", sqlite3_column_text(stmt, %d));\n", i))
+	}
+	g.output.WriteString("                    offset += sprintf(html + offset, \"</li>\\n\");\n")
+	g.output.WriteString("                }\n")
+	g.output.WriteString("                offset += sprintf(html + offset, \"</ul>\\n\");\n")
+	g.output.WriteString("                sqlite3_finalize(stmt);\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("        }\n\n")
+	g.output.WriteString("        offset += sprintf(html + offset, \"%s\", html_footer);\n")
+	g.output.WriteString("        response = MHD_create_response_from_buffer(strlen(html), (void*)html, MHD_RESPMEM_MUST_FREE);\n")
+	g.output.WriteString("        MHD_add_response_header(response, \"Content-Type\", \"text/html\");\n")
+	g.output.WriteString("        ret = MHD_queue_response(connection, MHD_HTTP_OK, response);\n")
+	g.output.WriteString("        MHD_destroy_response(response);\n")
+	g.output.WriteString("        return ret;\n")
+	g.output.WriteString("    }\n\n")
+}
@@ -0,0 +1,499 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/decorator"
+)
+
+// /graphql gives every struct a single flexible query surface on top of the
+// {Struct}_all/_find/_create/_update/_delete functions the REST and HTML
+// routes already call, so a client doesn't need a new route generated for
+// every shape of query it wants. Like the REST/HTML request parsing, it's a
+// small hand-rolled tokenizer/parser tied to exactly the subset of GraphQL
+// this needs - no external dependency, the same trade-off json_api.go and
+// uploads.go make.
+//
+// The subset: a single `{ ... }` selection set of top-level fields, each
+// either a table name (`users`, `users(id: 1)`, `users(where: {name: "x"},
+// limit: 10, offset: 0)`) or a `{table}_insert`/`{table}_update`/
+// `{table}_delete` mutation. `where`'s keys are flattened directly into the
+// field's argument list - there is no nested-object value support beyond
+// that one level. Filtering, limit and offset are applied in C over the
+// full result of {Struct}_all rather than pushed into SQL, so this works
+// identically across every SQLBackend dialect without any dialect-specific
+// query building. A field name that matches no table/mutation comes back
+// as a null value rather than a top-level error, which keeps a request
+// selecting several fields from failing entirely over one typo.
+
+// graphqlMutationName is the {table}_insert/_update/_delete mutation field
+// prefix for s - its struct name lowercased, matching render_{page}_page's
+// existing convention for deriving an identifier from a declared name.
+func graphqlMutationName(s *ast.StructDecl) string {
+	return strings.ToLower(s.Name)
+}
+
+// graphqlNonAutoFields returns s's non-array, non-autoincrement fields, in
+// the same order generateCreate/generateUpdate expect their arguments.
+func graphqlNonAutoFields(s *ast.StructDecl) []*ast.FieldDecl {
+	var fields []*ast.FieldDecl
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// generateGraphQLRuntime emits the tokenizer, argument/selection parser and
+// request-body query extractor every table's dispatch block in
+// generateGraphQLRoute shares. No-op unless the program has web routes and
+// at least one struct to query.
+func (g *CGenerator) generateGraphQLRuntime() {
+	if !g.hasWeb || len(g.structs) == 0 {
+		return
+	}
+
+	g.output.WriteString("// Hand-rolled GraphQL tokenizer/parser for the /graphql endpoint.\n")
+	g.output.WriteString(`typedef enum {
+    ZL_GQL_IDENT, ZL_GQL_LBRACE, ZL_GQL_RBRACE, ZL_GQL_LPAREN, ZL_GQL_RPAREN,
+    ZL_GQL_COLON, ZL_GQL_COMMA, ZL_GQL_STRING, ZL_GQL_INT, ZL_GQL_EOF
+} zl_gql_toktype;
+
+typedef struct {
+    zl_gql_toktype type;
+    char text[256];
+    long long ival;
+} zl_gql_token;
+
+typedef struct {
+    const char *src;
+    int pos;
+} zl_gql_lexer;
+
+typedef struct {
+    char key[64];
+    char value[256];
+    int is_string;
+} zl_gql_arg;
+
+static void zl_gql_next(zl_gql_lexer *lex, zl_gql_token *tok) {
+    while (lex->src[lex->pos] == ' ' || lex->src[lex->pos] == '\t' ||
+           lex->src[lex->pos] == '\n' || lex->src[lex->pos] == '\r') {
+        lex->pos++;
+    }
+    char c = lex->src[lex->pos];
+    tok->text[0] = '\0';
+    if (c == '\0') { tok->type = ZL_GQL_EOF; return; }
+    if (c == '{') { lex->pos++; tok->type = ZL_GQL_LBRACE; return; }
+    if (c == '}') { lex->pos++; tok->type = ZL_GQL_RBRACE; return; }
+    if (c == '(') { lex->pos++; tok->type = ZL_GQL_LPAREN; return; }
+    if (c == ')') { lex->pos++; tok->type = ZL_GQL_RPAREN; return; }
+    if (c == ':') { lex->pos++; tok->type = ZL_GQL_COLON; return; }
+    if (c == ',') { lex->pos++; tok->type = ZL_GQL_COMMA; return; }
+    if (c == '"') {
+        lex->pos++;
+        int n = 0;
+        while (lex->src[lex->pos] != '"' && lex->src[lex->pos] != '\0' && n < 255) {
+            tok->text[n++] = lex->src[lex->pos++];
+        }
+        if (lex->src[lex->pos] == '"') lex->pos++;
+        tok->text[n] = '\0';
+        tok->type = ZL_GQL_STRING;
+        return;
+    }
+    if (c == '-' || isdigit((unsigned char)c)) {
+        int n = 0;
+        if (c == '-') { tok->text[n++] = c; lex->pos++; }
+        while (isdigit((unsigned char)lex->src[lex->pos]) && n < 255) {
+            tok->text[n++] = lex->src[lex->pos++];
+        }
+        tok->text[n] = '\0';
+        tok->ival = strtoll(tok->text, NULL, 10);
+        tok->type = ZL_GQL_INT;
+        return;
+    }
+    int n = 0;
+    while ((isalnum((unsigned char)lex->src[lex->pos]) || lex->src[lex->pos] == '_') && n < 255) {
+        tok->text[n++] = lex->src[lex->pos++];
+    }
+    tok->text[n] = '\0';
+    tok->type = ZL_GQL_IDENT;
+}
+
+// zl_gql_parse_args parses a field's argument list up to and including the
+// closing ')' - the caller must already have consumed the opening '('. A
+// "where: {...}" value's inner key/value pairs are flattened directly into
+// args rather than nested, since nothing here looks more than one level
+// deep into an argument value.
+static void zl_gql_parse_args(zl_gql_lexer *lex, zl_gql_arg *args, int *argc) {
+    zl_gql_token tok;
+    zl_gql_next(lex, &tok);
+    while (tok.type != ZL_GQL_RPAREN && tok.type != ZL_GQL_EOF) {
+        if (tok.type != ZL_GQL_IDENT) {
+            zl_gql_next(lex, &tok);
+            continue;
+        }
+        char key[64];
+        strncpy(key, tok.text, sizeof(key) - 1);
+        key[sizeof(key) - 1] = '\0';
+        zl_gql_next(lex, &tok); // ':'
+        zl_gql_next(lex, &tok); // value
+        if (tok.type == ZL_GQL_LBRACE) {
+            zl_gql_next(lex, &tok);
+            while (tok.type != ZL_GQL_RBRACE && tok.type != ZL_GQL_EOF) {
+                if (tok.type != ZL_GQL_IDENT) {
+                    zl_gql_next(lex, &tok);
+                    continue;
+                }
+                char nkey[64];
+                strncpy(nkey, tok.text, sizeof(nkey) - 1);
+                nkey[sizeof(nkey) - 1] = '\0';
+                zl_gql_next(lex, &tok); // ':'
+                zl_gql_next(lex, &tok); // value
+                if (*argc < 16) {
+                    strncpy(args[*argc].key, nkey, sizeof(args[*argc].key) - 1);
+                    strncpy(args[*argc].value, tok.text, sizeof(args[*argc].value) - 1);
+                    args[*argc].is_string = (tok.type == ZL_GQL_STRING);
+                    (*argc)++;
+                }
+                zl_gql_next(lex, &tok);
+                if (tok.type == ZL_GQL_COMMA) zl_gql_next(lex, &tok);
+            }
+            zl_gql_next(lex, &tok); // consume '}', land on ',' or ')'
+        } else {
+            if (*argc < 16) {
+                strncpy(args[*argc].key, key, sizeof(args[*argc].key) - 1);
+                strncpy(args[*argc].value, tok.text, sizeof(args[*argc].value) - 1);
+                args[*argc].is_string = (tok.type == ZL_GQL_STRING);
+                (*argc)++;
+            }
+            zl_gql_next(lex, &tok);
+        }
+        if (tok.type == ZL_GQL_COMMA) zl_gql_next(lex, &tok);
+    }
+}
+
+// zl_gql_parse_selection parses a "{ field field ... }" selection set up to
+// and including the closing '}' - the caller must already have consumed
+// the opening '{'.
+static void zl_gql_parse_selection(zl_gql_lexer *lex, char sel[][64], int *selc) {
+    zl_gql_token tok;
+    zl_gql_next(lex, &tok);
+    *selc = 0;
+    while (tok.type != ZL_GQL_RBRACE && tok.type != ZL_GQL_EOF) {
+        if (tok.type == ZL_GQL_IDENT && *selc < 32) {
+            strncpy(sel[*selc], tok.text, 63);
+            sel[*selc][63] = '\0';
+            (*selc)++;
+        }
+        zl_gql_next(lex, &tok);
+    }
+}
+
+static const char* zl_gql_arg_str(zl_gql_arg *args, int argc, const char *key) {
+    for (int i = 0; i < argc; i++) {
+        if (strcmp(args[i].key, key) == 0) return args[i].value;
+    }
+    return NULL;
+}
+
+static long long zl_gql_arg_int(zl_gql_arg *args, int argc, const char *key) {
+    const char *v = zl_gql_arg_str(args, argc, key);
+    return v ? strtoll(v, NULL, 10) : 0;
+}
+
+// zl_gql_selected reports whether key should be written into a row's JSON
+// object - an empty selection set means "every field" (the same default a
+// REST client gets from {Struct}_to_json).
+static int zl_gql_selected(char sel[][64], int selc, const char *key) {
+    if (selc == 0) return 1;
+    for (int i = 0; i < selc; i++) {
+        if (strcmp(sel[i], key) == 0) return 1;
+    }
+    return 0;
+}
+
+// zl_gql_extract_query pulls the "query" string out of a {"query": "...",
+// "variables": {...}} request body, unescaping \" and \\ the way a real
+// JSON client would have escaped a query containing string arguments (e.g.
+// where's {name: "x"} value) - zl_json_extract_string can't be reused here since
+// it stops at the first '"', which a query like that contains well before
+// its actual end.
+static char* zl_gql_extract_query(const char *body) {
+    const char *p = strstr(body, "\"query\"");
+    if (!p) return NULL;
+    p = strchr(p + 7, ':');
+    if (!p) return NULL;
+    p++;
+    while (*p == ' ' || *p == '\n' || *p == '\t' || *p == '\r') p++;
+    if (*p != '"') return NULL;
+    p++;
+    char *out = (char*)malloc(strlen(p) + 1);
+    int n = 0;
+    while (*p && *p != '"') {
+        if (*p == '\\' && *(p + 1)) {
+            p++;
+            if (*p == 'n') out[n++] = '\n';
+            else if (*p == 't') out[n++] = '\t';
+            else out[n++] = *p;
+            p++;
+        } else {
+            out[n++] = *p++;
+        }
+    }
+    out[n] = '\0';
+    return out;
+}
+
+`)
+}
+
+// graphqlFieldWrite emits the "key": value write (with a leading comma
+// managed by the caller's ffirst flag) for one field of objVar into the
+// handler's out/offset buffer, gated by zl_gql_selected - the same
+// per-type formatting jsonFieldWrite uses for the REST JSON surface.
+func graphqlFieldWrite(field *ast.FieldDecl, objVar string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("    if (zl_gql_selected(sel, selc, \"%s\")) {\n", field.Name))
+	sb.WriteString("        if (!ffirst) offset += sprintf(out + offset, \",\");\n")
+	sb.WriteString("        ffirst = 0;\n")
+	switch field.Type {
+	case "int":
+		sb.WriteString(fmt.Sprintf("        offset += sprintf(out + offset, \"\\\"%s\\\": %%lld\", (long long)%s->%s);\n", field.Name, objVar, field.Name))
+	case "bool":
+		sb.WriteString(fmt.Sprintf("        offset += sprintf(out + offset, \"\\\"%s\\\": %%s\", %s->%s ? \"true\" : \"false\");\n", field.Name, objVar, field.Name))
+	case "float":
+		sb.WriteString(fmt.Sprintf("        offset += sprintf(out + offset, \"\\\"%s\\\": %%f\", %s->%s);\n", field.Name, objVar, field.Name))
+	default:
+		sb.WriteString(fmt.Sprintf("        offset += sprintf(out + offset, \"\\\"%s\\\": \\\"\");\n", field.Name))
+		sb.WriteString(fmt.Sprintf("        offset += zl_json_escape_into(out + offset, %s->%s);\n", objVar, field.Name))
+		sb.WriteString("        offset += sprintf(out + offset, \"\\\"\");\n")
+	}
+	sb.WriteString("    }\n")
+	return sb.String()
+}
+
+// graphqlArgValue renders the C expression that reads field's argument
+// value out of args, falling back to fallback (a literal default, or an
+// existing struct's current value on update) when the argument is absent.
+func graphqlArgValue(field *ast.FieldDecl, fallback string) string {
+	switch field.Type {
+	case "int", "bool":
+		return fmt.Sprintf("zl_gql_arg_str(args, argc, \"%s\") ? zl_gql_arg_int(args, argc, \"%s\") : %s",
+			field.Name, field.Name, fallback)
+	case "float":
+		return fmt.Sprintf("zl_gql_arg_str(args, argc, \"%s\") ? (double)zl_gql_arg_int(args, argc, \"%s\") : %s",
+			field.Name, field.Name, fallback)
+	default:
+		return fmt.Sprintf("zl_gql_arg_str(args, argc, \"%s\") ? zl_gql_arg_str(args, argc, \"%s\") : %s",
+			field.Name, field.Name, fallback)
+	}
+}
+
+// generateGraphQLQueryField emits the `if (!matched_field && strcmp(field_name,
+// "{table}") == 0) { ... }` block listing/filtering s, writing matching rows
+// (projected through the requested selection set) into the response's
+// "data" object.
+func (g *CGenerator) generateGraphQLQueryField(s *ast.StructDecl, tableName string) {
+	name := s.Name
+	fields := nonArrayFields(s)
+
+	g.output.WriteString(fmt.Sprintf("        if (!matched_field && strcmp(field_name, \"%s\") == 0) {\n", tableName))
+	g.output.WriteString("            matched_field = 1;\n")
+	g.output.WriteString("            int item_count = 0;\n")
+	g.output.WriteString(fmt.Sprintf("            %s** items = %s_all(&item_count);\n", name, name))
+	g.output.WriteString(fmt.Sprintf("            offset += sprintf(out + offset, \"\\\"%s\\\": [\");\n", tableName))
+	g.output.WriteString("            long long lim = zl_gql_arg_str(args, argc, \"limit\") ? zl_gql_arg_int(args, argc, \"limit\") : -1;\n")
+	g.output.WriteString("            long long skip = zl_gql_arg_str(args, argc, \"offset\") ? zl_gql_arg_int(args, argc, \"offset\") : 0;\n")
+	g.output.WriteString("            long long seen = 0;\n")
+	g.output.WriteString("            int emitted = 0;\n")
+	g.output.WriteString("            for (int i = 0; i < item_count; i++) {\n")
+	g.output.WriteString(fmt.Sprintf("                %s* obj = items[i];\n", name))
+	g.output.WriteString("                int keep = 1;\n")
+	for _, field := range fields {
+		switch field.Type {
+		case "int", "bool":
+			g.output.WriteString(fmt.Sprintf("                if (zl_gql_arg_str(args, argc, \"%s\") && (long long)obj->%s != zl_gql_arg_int(args, argc, \"%s\")) keep = 0;\n",
+				field.Name, field.Name, field.Name))
+		case "float":
+			g.output.WriteString(fmt.Sprintf("                if (zl_gql_arg_str(args, argc, \"%s\") && obj->%s != (double)zl_gql_arg_int(args, argc, \"%s\")) keep = 0;\n",
+				field.Name, field.Name, field.Name))
+		default:
+			g.output.WriteString(fmt.Sprintf("                if (zl_gql_arg_str(args, argc, \"%s\") && strcmp(obj->%s, zl_gql_arg_str(args, argc, \"%s\")) != 0) keep = 0;\n",
+				field.Name, field.Name, field.Name))
+		}
+	}
+	g.output.WriteString("                if (!keep) continue;\n")
+	g.output.WriteString("                if (seen < skip) { seen++; continue; }\n")
+	g.output.WriteString("                seen++;\n")
+	g.output.WriteString("                if (lim >= 0 && emitted >= lim) break;\n")
+	g.output.WriteString("                if (emitted > 0) offset += sprintf(out + offset, \",\");\n")
+	g.output.WriteString("                offset += sprintf(out + offset, \"{\");\n")
+	g.output.WriteString("                int ffirst = 1;\n")
+	for _, field := range fields {
+		g.output.WriteString(graphqlFieldWrite(field, "obj"))
+	}
+	g.output.WriteString("                offset += sprintf(out + offset, \"}\");\n")
+	g.output.WriteString("                emitted++;\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("            offset += sprintf(out + offset, \"]\");\n")
+	g.output.WriteString("        }\n")
+}
+
+// generateGraphQLMutationFields emits the {table}_insert/_update/_delete
+// blocks for s, each calling straight into the existing {Struct}_create/
+// _update/_delete functions the REST and HTML routes already use.
+func (g *CGenerator) generateGraphQLMutationFields(s *ast.StructDecl) {
+	name := s.Name
+	mutName := graphqlMutationName(s)
+	fields := graphqlNonAutoFields(s)
+
+	// {table}_insert
+	insertName := mutName + "_insert"
+	g.output.WriteString(fmt.Sprintf("        if (!matched_field && strcmp(field_name, \"%s\") == 0) {\n", insertName))
+	g.output.WriteString("            matched_field = 1;\n")
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		def := "0"
+		if g.mapType(field.Type) == "char*" {
+			def = "\"\""
+		}
+		args[i] = graphqlArgValue(field, def)
+	}
+	g.output.WriteString(fmt.Sprintf("            %s* obj = %s_create(%s);\n", name, name, strings.Join(args, ", ")))
+	g.output.WriteString(fmt.Sprintf("            offset += sprintf(out + offset, \"\\\"%s\\\": \");\n", insertName))
+	g.output.WriteString("            if (!obj) {\n")
+	g.output.WriteString("                offset += sprintf(out + offset, \"null\");\n")
+	g.output.WriteString("            } else {\n")
+	g.output.WriteString("                offset += sprintf(out + offset, \"{\");\n")
+	g.output.WriteString("                int ffirst = 1;\n")
+	for _, field := range nonArrayFields(s) {
+		g.output.WriteString(graphqlFieldWrite(field, "obj"))
+	}
+	g.output.WriteString("                offset += sprintf(out + offset, \"}\");\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("        }\n")
+
+	// {table}_update
+	updateName := mutName + "_update"
+	g.output.WriteString(fmt.Sprintf("        if (!matched_field && strcmp(field_name, \"%s\") == 0) {\n", updateName))
+	g.output.WriteString("            matched_field = 1;\n")
+	g.output.WriteString("            int64_t mut_id = zl_gql_arg_int(args, argc, \"id\");\n")
+	g.output.WriteString(fmt.Sprintf("            %s* existing = %s_find(mut_id);\n", name, name))
+	g.output.WriteString(fmt.Sprintf("            offset += sprintf(out + offset, \"\\\"%s\\\": \");\n", updateName))
+	g.output.WriteString("            if (!existing) {\n")
+	g.output.WriteString("                offset += sprintf(out + offset, \"null\");\n")
+	g.output.WriteString("            } else {\n")
+	updateArgs := make([]string, 0, len(fields)+1)
+	updateArgs = append(updateArgs, "mut_id")
+	for _, field := range fields {
+		updateArgs = append(updateArgs, graphqlArgValue(field, "existing->"+field.Name))
+	}
+	g.output.WriteString(fmt.Sprintf("                %s* obj = %s_update(%s);\n", name, name, strings.Join(updateArgs, ", ")))
+	g.output.WriteString("                if (!obj) {\n")
+	g.output.WriteString("                    offset += sprintf(out + offset, \"null\");\n")
+	g.output.WriteString("                } else {\n")
+	g.output.WriteString("                    offset += sprintf(out + offset, \"{\");\n")
+	g.output.WriteString("                    int ffirst = 1;\n")
+	for _, field := range nonArrayFields(s) {
+		g.output.WriteString(graphqlFieldWrite(field, "obj"))
+	}
+	g.output.WriteString("                    offset += sprintf(out + offset, \"}\");\n")
+	g.output.WriteString("                }\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("        }\n")
+
+	// {table}_delete
+	deleteName := mutName + "_delete"
+	g.output.WriteString(fmt.Sprintf("        if (!matched_field && strcmp(field_name, \"%s\") == 0) {\n", deleteName))
+	g.output.WriteString("            matched_field = 1;\n")
+	g.output.WriteString("            int ok = " + name + "_delete(zl_gql_arg_int(args, argc, \"id\"));\n")
+	g.output.WriteString(fmt.Sprintf("            offset += sprintf(out + offset, \"\\\"%s\\\": %%s\", ok ? \"true\" : \"false\");\n", deleteName))
+	g.output.WriteString("        }\n")
+}
+
+// generateGraphQLRoute emits the /graphql POST handler inside
+// handle_request, dispatching every struct's query and mutation fields.
+// No-op unless the program has web routes and at least one struct.
+func (g *CGenerator) generateGraphQLRoute() {
+	if !g.hasWeb || len(g.structs) == 0 {
+		return
+	}
+
+	g.output.WriteString("    // /graphql: a single flexible query surface over every table (see graphql.go).\n")
+	g.output.WriteString("    if (strcmp(url, \"/graphql\") == 0 && strcmp(method, \"POST\") == 0) {\n")
+	g.output.WriteString("        if (*con_cls == NULL) {\n")
+	g.output.WriteString("            *con_cls = (void*)1;\n")
+	g.output.WriteString("            return MHD_YES;\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString("        if (*upload_data_size == 0) {\n")
+	g.output.WriteString("            return MHD_YES;\n")
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        char *query = zl_gql_extract_query(upload_data);\n")
+	g.output.WriteString("        *upload_data_size = 0;\n")
+	g.output.WriteString("        char *out = (char*)malloc(65536);\n")
+	g.output.WriteString("        int offset = 0;\n\n")
+
+	g.output.WriteString("        if (!query) {\n")
+	g.output.WriteString("            offset += sprintf(out + offset, \"{\\\"errors\\\": [{\\\"message\\\": \\\"missing query\\\"}]}\");\n")
+	g.output.WriteString("        } else {\n")
+	g.output.WriteString("            zl_gql_lexer lex;\n")
+	g.output.WriteString("            lex.src = query;\n")
+	g.output.WriteString("            lex.pos = 0;\n")
+	g.output.WriteString("            zl_gql_token tok;\n")
+	g.output.WriteString("            zl_gql_next(&lex, &tok);\n")
+	g.output.WriteString("            if (tok.type == ZL_GQL_IDENT && (strcmp(tok.text, \"query\") == 0 || strcmp(tok.text, \"mutation\") == 0)) {\n")
+	g.output.WriteString("                zl_gql_next(&lex, &tok);\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("            if (tok.type != ZL_GQL_LBRACE) {\n")
+	g.output.WriteString("                offset += sprintf(out + offset, \"{\\\"errors\\\": [{\\\"message\\\": \\\"expected {\\\"}]}\");\n")
+	g.output.WriteString("            } else {\n")
+	g.output.WriteString("                offset += sprintf(out + offset, \"{\\\"data\\\": {\");\n")
+	g.output.WriteString("                int field_count = 0;\n")
+	g.output.WriteString("                zl_gql_next(&lex, &tok);\n")
+	g.output.WriteString("                while (tok.type == ZL_GQL_IDENT) {\n")
+	g.output.WriteString("                    char field_name[256];\n")
+	g.output.WriteString("                    strncpy(field_name, tok.text, sizeof(field_name) - 1);\n")
+	g.output.WriteString("                    field_name[sizeof(field_name) - 1] = '\\0';\n")
+	g.output.WriteString("                    zl_gql_arg args[16];\n")
+	g.output.WriteString("                    int argc = 0;\n")
+	g.output.WriteString("                    char sel[32][64];\n")
+	g.output.WriteString("                    int selc = 0;\n")
+	g.output.WriteString("                    zl_gql_next(&lex, &tok);\n")
+	g.output.WriteString("                    if (tok.type == ZL_GQL_LPAREN) {\n")
+	g.output.WriteString("                        zl_gql_parse_args(&lex, args, &argc);\n")
+	g.output.WriteString("                        zl_gql_next(&lex, &tok);\n")
+	g.output.WriteString("                    }\n")
+	g.output.WriteString("                    if (tok.type == ZL_GQL_LBRACE) {\n")
+	g.output.WriteString("                        zl_gql_parse_selection(&lex, sel, &selc);\n")
+	g.output.WriteString("                        zl_gql_next(&lex, &tok);\n")
+	g.output.WriteString("                    }\n\n")
+
+	g.output.WriteString("                    if (field_count > 0) offset += sprintf(out + offset, \",\");\n")
+	g.output.WriteString("                    int matched_field = 0;\n")
+	for _, s := range g.structs {
+		g.generateGraphQLQueryField(s, g.getTableName(s))
+		g.generateGraphQLMutationFields(s)
+	}
+	g.output.WriteString("                    if (!matched_field) {\n")
+	g.output.WriteString("                        offset += sprintf(out + offset, \"\\\"%s\\\": null\", field_name);\n")
+	g.output.WriteString("                    }\n")
+	g.output.WriteString("                    field_count++;\n")
+	g.output.WriteString("                }\n")
+	g.output.WriteString("                offset += sprintf(out + offset, \"}}\");\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("            free(query);\n")
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        response = MHD_create_response_from_buffer(strlen(out), (void*)out, MHD_RESPMEM_MUST_FREE);\n")
+	g.output.WriteString("        MHD_add_response_header(response, \"Content-Type\", \"application/json\");\n")
+	g.output.WriteString("        ret = MHD_queue_response(connection, MHD_HTTP_OK, response);\n")
+	g.output.WriteString("        MHD_destroy_response(response);\n")
+	g.output.WriteString("        return ret;\n")
+	g.output.WriteString("    }\n\n")
+}
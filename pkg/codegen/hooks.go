@@ -0,0 +1,201 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// Hooks and custom scalar functions are backed by libsqlite3's
+// sqlite3_update_hook/commit_hook/rollback_hook and
+// sqlite3_create_function_v2, following the pattern in mattn/go-sqlite3's
+// _example/hook - so, like FTS, everything here is a no-op on MySQL/Postgres.
+//
+// A hook declares event names only (before_insert, after_insert, ...):
+// pkg/parser captures the names and no event body (see ast.HookDecl). The
+// generated callback dispatches by table and operation and logs which
+// declared event fired; it runs no user code.
+
+// structHooks returns the hooks declared "on" an existing struct (tbl_name
+// dispatch, driving sqlite3_update_hook), in declaration order.
+func (g *CGenerator) structHooks() []*ast.HookDecl {
+	var hooks []*ast.HookDecl
+	for _, h := range g.hooks {
+		if h.StructName != "" {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// globalHookEvents reports whether any global (`hook { ... }`, no "on
+// <Struct>") block declares the given event, e.g. "on_commit"/"on_rollback".
+func (g *CGenerator) globalHookEvents(event string) bool {
+	for _, h := range g.hooks {
+		if h.StructName != "" {
+			continue
+		}
+		for _, e := range h.Events {
+			if e == event {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hookOpForEvent maps a declared event name to the SQLite update-hook
+// operation constant it corresponds to; "" if the event doesn't name one of
+// insert/update/delete (e.g. it's a typo or a global on_commit/on_rollback
+// event parsed under a struct by mistake).
+func hookOpForEvent(event string) string {
+	switch {
+	case strings.Contains(event, "insert"):
+		return "SQLITE_INSERT"
+	case strings.Contains(event, "update"):
+		return "SQLITE_UPDATE"
+	case strings.Contains(event, "delete"):
+		return "SQLITE_DELETE"
+	default:
+		return ""
+	}
+}
+
+// generateHookCallbacks emits the static C callbacks hook declarations
+// compile to, ahead of main(). No-op when there are no hooks, or the target
+// dialect isn't sqlite.
+func (g *CGenerator) generateHookCallbacks() {
+	if len(g.hooks) == 0 || g.db.Name() != "sqlite" {
+		return
+	}
+
+	if hooks := g.structHooks(); len(hooks) > 0 {
+		g.output.WriteString("// Dispatches sqlite3_update_hook to the declared `hook on <Struct>` events.\n")
+		g.output.WriteString("static void zl_update_hook(void *data, int op, const char *db_name, const char *tbl_name, sqlite3_int64 rowid) {\n")
+		for _, h := range hooks {
+			tableName := h.StructName
+			for _, s := range g.structs {
+				if s.Name == h.StructName {
+					tableName = g.getTableName(s)
+					break
+				}
+			}
+			g.output.WriteString(fmt.Sprintf("    if (tbl_name && strcmp(tbl_name, \"%s\") == 0) {\n", tableName))
+			for _, event := range h.Events {
+				sqliteOp := hookOpForEvent(event)
+				if sqliteOp == "" {
+					continue
+				}
+				g.output.WriteString(fmt.Sprintf("        if (op == %s) printf(\"[hook] %s.%s rowid=%%lld\\n\", (long long)rowid);\n",
+					sqliteOp, tableName, event))
+			}
+			g.output.WriteString("    }\n")
+		}
+		g.output.WriteString("}\n\n")
+	}
+
+	if g.globalHookEvents("on_commit") {
+		g.output.WriteString("static int zl_commit_hook(void *data) {\n")
+		g.output.WriteString("    printf(\"[hook] on_commit\\n\");\n")
+		g.output.WriteString("    return 0; // nonzero aborts the commit\n")
+		g.output.WriteString("}\n\n")
+	}
+
+	if g.globalHookEvents("on_rollback") {
+		g.output.WriteString("static void zl_rollback_hook(void *data) {\n")
+		g.output.WriteString("    printf(\"[hook] on_rollback\\n\");\n")
+		g.output.WriteString("}\n\n")
+	}
+}
+
+// sqliteValueGetter maps a ZeLang type to the sqlite3_value_* accessor an
+// argument is read through; sqliteResultSetter below maps it to the
+// sqlite3_result_* setter a result is returned through.
+func sqliteValueGetter(zlType string) string {
+	switch zlType {
+	case "int", "bool":
+		return "sqlite3_value_int64"
+	case "float":
+		return "sqlite3_value_double"
+	default:
+		return "(const char*)sqlite3_value_text"
+	}
+}
+
+func sqliteResultSetter(zlType string) string {
+	switch zlType {
+	case "int", "bool":
+		return "sqlite3_result_int64"
+	case "float":
+		return "sqlite3_result_double"
+	default:
+		return "sqlite3_result_text"
+	}
+}
+
+// generateCustomFunctions emits, for each `function <name>(...) -> type`
+// declaration, a static sqlite3_create_function_v2 callback that converts
+// argv and forwards to an externally-implemented C function of the same
+// name - the function body itself isn't compiled (see the comment at the
+// top of this file). No-op when there are no declared functions, or the target
+// dialect isn't sqlite.
+func (g *CGenerator) generateCustomFunctions() {
+	if len(g.functions) == 0 || g.db.Name() != "sqlite" {
+		return
+	}
+
+	for _, fn := range g.functions {
+		cReturnType := g.mapType(fn.ReturnType)
+		paramTypes := make([]string, len(fn.Parameters))
+		for i, p := range fn.Parameters {
+			paramTypes[i] = g.mapType(p.Type)
+		}
+		g.output.WriteString(fmt.Sprintf("// ZeLang function bodies aren't compiled yet; implement %s yourself:\n", fn.Name))
+		g.output.WriteString(fmt.Sprintf("extern %s %s(%s);\n", cReturnType, fn.Name, strings.Join(paramTypes, ", ")))
+
+		g.output.WriteString(fmt.Sprintf("static void zl_fn_%s(sqlite3_context *ctx, int argc, sqlite3_value **argv) {\n", fn.Name))
+		args := make([]string, len(fn.Parameters))
+		for i, p := range fn.Parameters {
+			cType := g.mapType(p.Type)
+			g.output.WriteString(fmt.Sprintf("    %s arg%d = (%s)%s(argv[%d]);\n",
+				cType, i, cType, sqliteValueGetter(p.Type), i))
+			args[i] = fmt.Sprintf("arg%d", i)
+		}
+		g.output.WriteString(fmt.Sprintf("    %s result = %s(%s);\n", cReturnType, fn.Name, strings.Join(args, ", ")))
+		if cReturnType == "char*" {
+			g.output.WriteString(fmt.Sprintf("    %s(ctx, result, -1, SQLITE_TRANSIENT);\n", sqliteResultSetter(fn.ReturnType)))
+		} else {
+			g.output.WriteString(fmt.Sprintf("    %s(ctx, result);\n", sqliteResultSetter(fn.ReturnType)))
+		}
+		g.output.WriteString("}\n\n")
+	}
+}
+
+// generateHookRegistrations emits the sqlite3_update_hook/commit_hook/
+// rollback_hook and sqlite3_create_function_v2 registrations, called from
+// generateMain/generateWebMain right after sqlite3_open. No-op when there is
+// nothing to register, or the target dialect isn't sqlite.
+func (g *CGenerator) generateHookRegistrations() {
+	if g.db.Name() != "sqlite" {
+		return
+	}
+
+	if len(g.structHooks()) > 0 {
+		g.output.WriteString("    sqlite3_update_hook(db, zl_update_hook, NULL);\n")
+	}
+	if g.globalHookEvents("on_commit") {
+		g.output.WriteString("    sqlite3_commit_hook(db, zl_commit_hook, NULL);\n")
+	}
+	if g.globalHookEvents("on_rollback") {
+		g.output.WriteString("    sqlite3_rollback_hook(db, zl_rollback_hook, NULL);\n")
+	}
+	for _, fn := range g.functions {
+		g.output.WriteString(fmt.Sprintf(
+			"    sqlite3_create_function_v2(db, \"%s\", %d, SQLITE_UTF8, NULL, zl_fn_%s, NULL, NULL, NULL);\n",
+			fn.Name, len(fn.Parameters), fn.Name))
+	}
+	if len(g.structHooks()) > 0 || g.globalHookEvents("on_commit") || g.globalHookEvents("on_rollback") || len(g.functions) > 0 {
+		g.output.WriteString("\n")
+	}
+}
@@ -0,0 +1,318 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/decorator"
+)
+
+// Every struct gets a JSON REST surface alongside its HTML form routes:
+// GET/POST /api/{table} and GET/PUT/DELETE /api/{table}/{id}, so a
+// zelang-generated binary can serve a SPA/mobile client as well as
+// server-rendered pages. Like the HTML form handler, request bodies are
+// parsed with a tiny hand-rolled reader tied to the exact shape
+// {Struct}_to_json itself emits - no external JSON dependency, the same
+// trade-off fs_storage.go makes for its on-disk records.
+
+// generateJSONRuntime emits the JSON field extraction helpers the generated
+// {Struct}_to_json/{Struct}_from_json functions share. Every struct gets
+// those regardless of g.hasWeb (see generateCRUD), so this runtime has to be
+// unconditional too, not just gated on the program having web routes.
+func (g *CGenerator) generateJSONRuntime() {
+	if len(g.structs) == 0 {
+		return
+	}
+
+	g.output.WriteString("// JSON helpers for the /api/{table} REST surface.\n")
+	g.output.WriteString(`static long long zl_json_extract_int(const char *json, const char *key) {
+    char needle[256];
+    snprintf(needle, sizeof(needle), "\"%s\"", key);
+    const char *p = strstr(json, needle);
+    if (!p) return 0;
+    p = strchr(p + strlen(needle), ':');
+    if (!p) return 0;
+    return strtoll(p + 1, NULL, 10);
+}
+
+static double zl_json_extract_float(const char *json, const char *key) {
+    char needle[256];
+    snprintf(needle, sizeof(needle), "\"%s\"", key);
+    const char *p = strstr(json, needle);
+    if (!p) return 0.0;
+    p = strchr(p + strlen(needle), ':');
+    if (!p) return 0.0;
+    return strtod(p + 1, NULL);
+}
+
+static char* zl_json_extract_string(const char *json, const char *key) {
+    char needle[256];
+    snprintf(needle, sizeof(needle), "\"%s\"", key);
+    const char *p = strstr(json, needle);
+    if (!p) return strdup("");
+    p = strchr(p + strlen(needle), ':');
+    if (!p) return strdup("");
+    p++;
+    while (*p == ' ') p++;
+    if (*p != '"') return strdup("");
+    p++;
+    const char *end = strchr(p, '"');
+    if (!end) return strdup("");
+    size_t len = end - p;
+    char *out = (char*)malloc(len + 1);
+    memcpy(out, p, len);
+    out[len] = '\0';
+    return out;
+}
+
+// zl_json_escape_into writes value into buf, escaping '"' and '\\', and
+// returns the number of bytes written (not counting the terminator).
+static int zl_json_escape_into(char *buf, const char *value) {
+    int n = 0;
+    for (const char *c = value; *c; c++) {
+        if (*c == '"' || *c == '\\') buf[n++] = '\\';
+        buf[n++] = *c;
+    }
+    buf[n] = '\0';
+    return n;
+}
+
+`)
+}
+
+// jsonFieldWrite emits the offset += sprintf(...) line(s) appending one
+// field of obj to buf as a "key": value pair.
+func (g *CGenerator) jsonFieldWrite(field *ast.FieldDecl, objVar string) string {
+	switch field.Type {
+	case "int":
+		return fmt.Sprintf("    offset += sprintf(buf + offset, \"\\\"%s\\\": %%lld\", (long long)%s->%s);\n", field.Name, objVar, field.Name)
+	case "bool":
+		return fmt.Sprintf("    offset += sprintf(buf + offset, \"\\\"%s\\\": %%s\", %s->%s ? \"true\" : \"false\");\n", field.Name, objVar, field.Name)
+	case "float":
+		return fmt.Sprintf("    offset += sprintf(buf + offset, \"\\\"%s\\\": %%f\", %s->%s);\n", field.Name, objVar, field.Name)
+	default:
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("    offset += sprintf(buf + offset, \"\\\"%s\\\": \\\"\");\n", field.Name))
+		sb.WriteString(fmt.Sprintf("    offset += zl_json_escape_into(buf + offset, %s->%s);\n", objVar, field.Name))
+		sb.WriteString("    offset += sprintf(buf + offset, \"\\\"\");\n")
+		return sb.String()
+	}
+}
+
+// generateToJSON emits {Struct}_to_json(obj), rendering every non-array
+// field as a JSON object, and {Struct}_list_to_json(items, count) wrapping
+// a result set as a JSON array of the same.
+func (g *CGenerator) generateToJSON(s *ast.StructDecl) {
+	name := s.Name
+	fields := nonArrayFields(s)
+
+	g.output.WriteString(fmt.Sprintf("char* %s_to_json(%s* obj) {\n", name, name))
+	g.output.WriteString("    char* buf = (char*)malloc(4096);\n")
+	g.output.WriteString("    int offset = 0;\n")
+	g.output.WriteString("    offset += sprintf(buf + offset, \"{\");\n")
+	for i, field := range fields {
+		g.output.WriteString(g.jsonFieldWrite(field, "obj"))
+		if i < len(fields)-1 {
+			g.output.WriteString("    offset += sprintf(buf + offset, \", \");\n")
+		}
+	}
+	g.output.WriteString("    offset += sprintf(buf + offset, \"}\");\n")
+	g.output.WriteString("    return buf;\n")
+	g.output.WriteString("}\n\n")
+
+	g.output.WriteString(fmt.Sprintf("char* %s_list_to_json(%s** items, int count) {\n", name, name))
+	g.output.WriteString("    char* buf = (char*)malloc((size_t)(count + 1) * 4096);\n")
+	g.output.WriteString("    int offset = 0;\n")
+	g.output.WriteString("    offset += sprintf(buf + offset, \"[\");\n")
+	g.output.WriteString("    for (int i = 0; i < count; i++) {\n")
+	g.output.WriteString("        if (i > 0) offset += sprintf(buf + offset, \",\");\n")
+	g.output.WriteString(fmt.Sprintf("        char* item_json = %s_to_json(items[i]);\n", name))
+	g.output.WriteString("        offset += sprintf(buf + offset, \"%s\", item_json);\n")
+	g.output.WriteString("        free(item_json);\n")
+	g.output.WriteString("    }\n")
+	g.output.WriteString("    offset += sprintf(buf + offset, \"]\");\n")
+	g.output.WriteString("    return buf;\n")
+	g.output.WriteString("}\n\n")
+}
+
+// generateFromJSON emits {Struct}_from_json(json), parsing a request body
+// of the shape {Struct}_to_json itself produces into a freshly-allocated
+// struct - used by the create/update REST routes.
+func (g *CGenerator) generateFromJSON(s *ast.StructDecl) {
+	name := s.Name
+	g.output.WriteString(fmt.Sprintf("%s* %s_from_json(const char* json) {\n", name, name))
+	g.output.WriteString(fmt.Sprintf("    %s* obj = (%s*)malloc(sizeof(%s));\n", name, name, name))
+	for _, field := range nonArrayFields(s) {
+		switch field.Type {
+		case "int":
+			g.output.WriteString(fmt.Sprintf("    obj->%s = zl_json_extract_int(json, \"%s\");\n", field.Name, field.Name))
+		case "bool":
+			g.output.WriteString(fmt.Sprintf("    obj->%s = (int)zl_json_extract_int(json, \"%s\");\n", field.Name, field.Name))
+		case "float":
+			g.output.WriteString(fmt.Sprintf("    obj->%s = zl_json_extract_float(json, \"%s\");\n", field.Name, field.Name))
+		default:
+			g.output.WriteString(fmt.Sprintf("    obj->%s = zl_json_extract_string(json, \"%s\");\n", field.Name, field.Name))
+		}
+	}
+	g.output.WriteString("    return obj;\n")
+	g.output.WriteString("}\n\n")
+}
+
+// jsonErrorResponse emits a "queue this JSON error and return" block,
+// reused across the REST routes' negotiation/validation failures.
+func jsonErrorResponse(status, message string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("        const char* err = \"{\\\"error\\\": \\\"%s\\\"}\";\n", message))
+	sb.WriteString("        response = MHD_create_response_from_buffer(strlen(err), (void*)err, MHD_RESPMEM_PERSISTENT);\n")
+	sb.WriteString("        MHD_add_response_header(response, \"Content-Type\", \"application/json\");\n")
+	sb.WriteString(fmt.Sprintf("        ret = MHD_queue_response(connection, %s, response);\n", status))
+	sb.WriteString("        MHD_destroy_response(response);\n")
+	sb.WriteString("        return ret;\n")
+	return sb.String()
+}
+
+// generateAPIRoutes emits the /api/{table} and /api/{table}/{id} routes for
+// s inside handle_request: GET list, GET/PUT/DELETE by id, and POST create.
+// On /api/{table}, Accept negotiation rejects a request that explicitly
+// wants something other than JSON; the /api/{table}/{id} routes do not
+// check Accept. Content-Type on POST picks between parsing the body as JSON
+// or as the existing form-urlencoded helper, so create works from a browser
+// form or a JSON-speaking client; PUT always parses its body as JSON.
+func (g *CGenerator) generateAPIRoutes(s *ast.StructDecl, tableName string) {
+	name := s.Name
+	collectionURL := fmt.Sprintf("/api/%s", tableName)
+	memberPrefix := collectionURL + "/"
+
+	g.output.WriteString(fmt.Sprintf("    // JSON REST API for %s\n", tableName))
+	g.output.WriteString(fmt.Sprintf("    if (strcmp(url, \"%s\") == 0) {\n", collectionURL))
+	g.output.WriteString("        const char* accept = MHD_lookup_connection_value(connection, MHD_HEADER_KIND, \"Accept\");\n")
+	g.output.WriteString("        if (accept && !strstr(accept, \"json\") && !strstr(accept, \"*/*\")) {\n")
+	g.output.WriteString(jsonErrorResponse("MHD_HTTP_NOT_ACCEPTABLE", "not acceptable"))
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        if (strcmp(method, \"GET\") == 0) {\n")
+	g.output.WriteString("            int count = 0;\n")
+	g.output.WriteString(fmt.Sprintf("            %s** items = %s_all(&count);\n", name, name))
+	g.output.WriteString(fmt.Sprintf("            char* json = %s_list_to_json(items, count);\n", name))
+	g.output.WriteString("            response = MHD_create_response_from_buffer(strlen(json), (void*)json, MHD_RESPMEM_MUST_FREE);\n")
+	g.output.WriteString("            MHD_add_response_header(response, \"Content-Type\", \"application/json\");\n")
+	g.output.WriteString("            ret = MHD_queue_response(connection, MHD_HTTP_OK, response);\n")
+	g.output.WriteString("            MHD_destroy_response(response);\n")
+	g.output.WriteString("            return ret;\n")
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        if (strcmp(method, \"POST\") == 0) {\n")
+	g.output.WriteString("            if (*con_cls == NULL) {\n")
+	g.output.WriteString("                *con_cls = (void*)1;\n")
+	g.output.WriteString("                return MHD_YES;\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("            if (*upload_data_size != 0) {\n")
+	g.output.WriteString("                const char* content_type = MHD_lookup_connection_value(connection, MHD_HEADER_KIND, \"Content-Type\");\n")
+	g.output.WriteString(fmt.Sprintf("                %s* obj;\n", name))
+	g.output.WriteString("                if (content_type && strstr(content_type, \"json\")) {\n")
+	g.output.WriteString(fmt.Sprintf("                    obj = %s_from_json(upload_data);\n", name))
+	g.output.WriteString("                } else {\n")
+	g.output.WriteString("                    char fields[10][256];\n")
+	g.output.WriteString("                    char values[10][256];\n")
+	g.output.WriteString("                    int count;\n")
+	g.output.WriteString("                    parse_form_data(upload_data, fields, values, &count);\n")
+	g.output.WriteString(fmt.Sprintf("                    obj = (%s*)malloc(sizeof(%s));\n", name, name))
+	for _, field := range nonArrayFields(s) {
+		cType := g.mapType(field.Type)
+		if cType == "char*" {
+			g.output.WriteString(fmt.Sprintf("                    obj->%s = \"\";\n", field.Name))
+		} else {
+			g.output.WriteString(fmt.Sprintf("                    obj->%s = 0;\n", field.Name))
+		}
+	}
+	g.output.WriteString("                    for (int i = 0; i < count; i++) {\n")
+	for _, field := range nonArrayFields(s) {
+		cType := g.mapType(field.Type)
+		if cType == "char*" {
+			g.output.WriteString(fmt.Sprintf("                        if (strcmp(fields[i], \"%s\") == 0) obj->%s = strdup(values[i]);\n", field.Name, field.Name))
+		} else if field.Type == "bool" {
+			g.output.WriteString(fmt.Sprintf("                        if (strcmp(fields[i], \"%s\") == 0) obj->%s = 1;\n", field.Name, field.Name))
+		} else {
+			g.output.WriteString(fmt.Sprintf("                        if (strcmp(fields[i], \"%s\") == 0) obj->%s = atoll(values[i]);\n", field.Name, field.Name))
+		}
+	}
+	g.output.WriteString("                    }\n")
+	g.output.WriteString("                }\n\n")
+
+	createArgs := []string{}
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+		createArgs = append(createArgs, "obj->"+field.Name)
+	}
+	g.output.WriteString(fmt.Sprintf("                %s* created = %s_create(%s);\n", name, name, strings.Join(createArgs, ", ")))
+	g.output.WriteString(fmt.Sprintf("                char* json = %s_to_json(created);\n", name))
+	g.output.WriteString("                *upload_data_size = 0;\n")
+	g.output.WriteString("                response = MHD_create_response_from_buffer(strlen(json), (void*)json, MHD_RESPMEM_MUST_FREE);\n")
+	g.output.WriteString("                MHD_add_response_header(response, \"Content-Type\", \"application/json\");\n")
+	g.output.WriteString("                ret = MHD_queue_response(connection, MHD_HTTP_CREATED, response);\n")
+	g.output.WriteString("                MHD_destroy_response(response);\n")
+	g.output.WriteString("                return ret;\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("            return MHD_YES;\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString("    }\n\n")
+
+	g.output.WriteString(fmt.Sprintf("    if (strncmp(url, \"%s\", %d) == 0 && strlen(url) > %d) {\n", memberPrefix, len(memberPrefix), len(memberPrefix)))
+	g.output.WriteString(fmt.Sprintf("        int64_t id = atoll(url + %d);\n\n", len(memberPrefix)))
+
+	g.output.WriteString("        if (strcmp(method, \"GET\") == 0) {\n")
+	g.output.WriteString(fmt.Sprintf("            %s* obj = %s_find(id);\n", name, name))
+	g.output.WriteString("            if (!obj) {\n")
+	g.output.WriteString(jsonErrorResponse("MHD_HTTP_NOT_FOUND", "not found"))
+	g.output.WriteString("            }\n")
+	g.output.WriteString(fmt.Sprintf("            char* json = %s_to_json(obj);\n", name))
+	g.output.WriteString("            response = MHD_create_response_from_buffer(strlen(json), (void*)json, MHD_RESPMEM_MUST_FREE);\n")
+	g.output.WriteString("            MHD_add_response_header(response, \"Content-Type\", \"application/json\");\n")
+	g.output.WriteString("            ret = MHD_queue_response(connection, MHD_HTTP_OK, response);\n")
+	g.output.WriteString("            MHD_destroy_response(response);\n")
+	g.output.WriteString("            return ret;\n")
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        if (strcmp(method, \"DELETE\") == 0) {\n")
+	g.output.WriteString(fmt.Sprintf("            int ok = %s_delete(id);\n", name))
+	g.output.WriteString("            const char* json = ok ? \"{\\\"deleted\\\": true}\" : \"{\\\"deleted\\\": false}\";\n")
+	g.output.WriteString("            response = MHD_create_response_from_buffer(strlen(json), (void*)json, MHD_RESPMEM_PERSISTENT);\n")
+	g.output.WriteString("            MHD_add_response_header(response, \"Content-Type\", \"application/json\");\n")
+	g.output.WriteString("            ret = MHD_queue_response(connection, ok ? MHD_HTTP_OK : MHD_HTTP_NOT_FOUND, response);\n")
+	g.output.WriteString("            MHD_destroy_response(response);\n")
+	g.output.WriteString("            return ret;\n")
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString("        if (strcmp(method, \"PUT\") == 0) {\n")
+	g.output.WriteString("            if (*con_cls == NULL) {\n")
+	g.output.WriteString("                *con_cls = (void*)1;\n")
+	g.output.WriteString("                return MHD_YES;\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("            if (*upload_data_size != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("                %s* patch = %s_from_json(upload_data);\n", name, name))
+	updateArgs := []string{"id"}
+	for _, field := range s.Fields {
+		if field.IsArray || decorator.IsAutoIncrement(field.Decorators) {
+			continue
+		}
+		updateArgs = append(updateArgs, "patch->"+field.Name)
+	}
+	g.output.WriteString(fmt.Sprintf("                %s* updated = %s_update(%s);\n", name, name, strings.Join(updateArgs, ", ")))
+	g.output.WriteString("                *upload_data_size = 0;\n")
+	g.output.WriteString("                if (!updated) {\n")
+	g.output.WriteString(jsonErrorResponse("MHD_HTTP_NOT_FOUND", "not found"))
+	g.output.WriteString("                }\n")
+	g.output.WriteString(fmt.Sprintf("                char* json = %s_to_json(updated);\n", name))
+	g.output.WriteString("                response = MHD_create_response_from_buffer(strlen(json), (void*)json, MHD_RESPMEM_MUST_FREE);\n")
+	g.output.WriteString("                MHD_add_response_header(response, \"Content-Type\", \"application/json\");\n")
+	g.output.WriteString("                ret = MHD_queue_response(connection, MHD_HTTP_OK, response);\n")
+	g.output.WriteString("                MHD_destroy_response(response);\n")
+	g.output.WriteString("                return ret;\n")
+	g.output.WriteString("            }\n")
+	g.output.WriteString("            return MHD_YES;\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString("    }\n\n")
+}
@@ -0,0 +1,446 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/decorator"
+)
+
+// defaultSchemaDir and defaultMigrationsDir are where CGenerator persists
+// its schema snapshot and writes generated migrations, mirroring the
+// conventional layout xormigrate-style tools default to.
+const (
+	defaultSchemaDir     = ".zelang"
+	defaultMigrationsDir = "migrations"
+)
+
+// schemaField/schemaStruct/dbSchema are the persisted shape of
+// <schemaDir>/schema.json: CGenerator's view of what the database looked
+// like as of the last generated migration.
+type schemaField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type schemaStruct struct {
+	Name   string        `json:"name"`
+	Fields []schemaField `json:"fields"`
+}
+
+type dbSchema struct {
+	Structs []schemaStruct `json:"structs"`
+}
+
+func loadSchema(path string) (dbSchema, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return dbSchema{}, nil
+	}
+	if err != nil {
+		return dbSchema{}, err
+	}
+	var s dbSchema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return dbSchema{}, err
+	}
+	return s, nil
+}
+
+func saveSchema(path string, s dbSchema) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func snapshotSchema(structs []*ast.StructDecl) dbSchema {
+	var s dbSchema
+	for _, st := range structs {
+		ss := schemaStruct{Name: st.Name}
+		for _, f := range nonArrayFields(st) {
+			ss.Fields = append(ss.Fields, schemaField{Name: f.Name, Type: f.Type})
+		}
+		s.Structs = append(s.Structs, ss)
+	}
+	return s
+}
+
+// columnChange is one column-level difference the migration generator turns
+// into a pair of ALTER TABLE statements (forward and down).
+type columnChange struct {
+	Kind      string // "add", "drop", "rename"
+	Table     string
+	Column    string
+	OldColumn string
+	Type      string
+}
+
+// diffSchema compares old against the current AST, honoring @renamedFrom so
+// a rename is recorded as a rename instead of a drop+add that would lose
+// the column's data. Scoped to column add/drop/rename - there's no decorator
+// yet marking a field as indexed, so CREATE INDEX generation is out of scope
+// until one exists.
+func (g *CGenerator) diffSchema(old dbSchema, structs []*ast.StructDecl) []columnChange {
+	oldFieldsByStruct := map[string]map[string]schemaField{}
+	for _, s := range old.Structs {
+		fields := map[string]schemaField{}
+		for _, f := range s.Fields {
+			fields[f.Name] = f
+		}
+		oldFieldsByStruct[s.Name] = fields
+	}
+
+	var changes []columnChange
+	for _, st := range structs {
+		tableName := g.getTableName(st)
+		oldFields := oldFieldsByStruct[st.Name]
+		matchedOld := map[string]bool{}
+
+		for _, f := range nonArrayFields(st) {
+			if renamedFrom, ok := decorator.RenamedFrom(f.Decorators); ok {
+				if _, ok := oldFields[renamedFrom]; ok {
+					changes = append(changes, columnChange{
+						Kind: "rename", Table: tableName, Column: f.Name, OldColumn: renamedFrom, Type: f.Type,
+					})
+					matchedOld[renamedFrom] = true
+					continue
+				}
+			}
+			if _, ok := oldFields[f.Name]; ok {
+				matchedOld[f.Name] = true
+				continue
+			}
+			changes = append(changes, columnChange{Kind: "add", Table: tableName, Column: f.Name, Type: f.Type})
+		}
+
+		for name, f := range oldFields {
+			if !matchedOld[name] {
+				changes = append(changes, columnChange{Kind: "drop", Table: tableName, Column: name, Type: f.Type})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Table+changes[i].Column < changes[j].Table+changes[j].Column
+	})
+	return changes
+}
+
+// alterStatements returns the forward and down SQL for one column change.
+func (g *CGenerator) alterStatements(change columnChange, currentColumns []schemaField) (up, down []string) {
+	colType := g.db.MapSQLType(change.Type, false)
+	switch change.Kind {
+	case "add":
+		up = []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", change.Table, change.Column, colType)}
+		// The down-recreate must target the pre-add schema, so drop the
+		// column this migration is adding back out of currentColumns first -
+		// otherwise the SQLite table-recreate below copies it right back in.
+		down = g.dropColumnStatements(change.Table, change.Column, withoutColumn(currentColumns, change.Column))
+	case "drop":
+		up = g.dropColumnStatements(change.Table, change.Column, currentColumns)
+		down = []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", change.Table, change.Column, colType)}
+	case "rename":
+		up = []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", change.Table, change.OldColumn, change.Column)}
+		down = []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", change.Table, change.Column, change.OldColumn)}
+	}
+	return
+}
+
+// withoutColumn returns columns with column removed, for building the
+// pre-migration schema a down-migration needs to recreate against.
+func withoutColumn(columns []schemaField, column string) []schemaField {
+	out := make([]schemaField, 0, len(columns))
+	for _, f := range columns {
+		if f.Name != column {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// dropColumnStatements drops a column. MySQL and Postgres support DROP
+// COLUMN directly; SQLite's C API predates it, so this recreates the table
+// without the dropped column instead, the standard SQLite workaround.
+// currentColumns is the table's column list with the drop already applied
+// (the schema CGenerator is migrating towards).
+func (g *CGenerator) dropColumnStatements(table, column string, currentColumns []schemaField) []string {
+	if g.db.Name() != "sqlite" {
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)}
+	}
+
+	names := make([]string, 0, len(currentColumns))
+	defs := make([]string, 0, len(currentColumns))
+	for _, f := range currentColumns {
+		names = append(names, f.Name)
+		defs = append(defs, fmt.Sprintf("%s %s", f.Name, g.db.MapSQLType(f.Type, false)))
+	}
+	cols := strings.Join(names, ", ")
+	tmp := table + "_migrate_tmp"
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s (%s)", tmp, strings.Join(defs, ", ")),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", tmp, cols, cols, table),
+		fmt.Sprintf("DROP TABLE %s", table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmp, table),
+	}
+}
+
+var migrationFilePattern = regexp.MustCompile(`^(\d{4})_`)
+
+// nextMigrationVersion scans dir for existing NNNN_*.c migration files and
+// returns one past the highest version found (1 if dir has none yet).
+func nextMigrationVersion(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	max := 0
+	for _, e := range entries {
+		m := migrationFilePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		if n, err := strconv.Atoi(m[1]); err == nil && n > max {
+			max = n
+		}
+	}
+	return max + 1, nil
+}
+
+// migrationSlug builds a short descriptive filename fragment from the first
+// change in the set, e.g. "add_email_to_user".
+func migrationSlug(changes []columnChange) string {
+	c := changes[0]
+	var slug string
+	switch c.Kind {
+	case "add":
+		slug = fmt.Sprintf("add_%s_to_%s", c.Column, c.Table)
+	case "drop":
+		slug = fmt.Sprintf("drop_%s_from_%s", c.Column, c.Table)
+	case "rename":
+		slug = fmt.Sprintf("rename_%s_to_%s_on_%s", c.OldColumn, c.Column, c.Table)
+	}
+	if len(changes) > 1 {
+		slug += "_and_more"
+	}
+	return slug
+}
+
+// GenerateMigration compares the program's current struct shape against the
+// persisted <defaultSchemaDir>/schema.json snapshot, and if anything
+// changed, writes a new numbered migration file under defaultMigrationsDir
+// plus an updated snapshot. Returns ("", "", nil) when there is nothing to
+// migrate. Migrations are filesystem-backed by design (see request body);
+// callers that can't or don't want disk I/O during Generate should not call
+// this.
+func (g *CGenerator) GenerateMigration(structs []*ast.StructDecl) (filename string, source string, err error) {
+	schemaPath := filepath.Join(defaultSchemaDir, "schema.json")
+	old, err := loadSchema(schemaPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	changes := g.diffSchema(old, structs)
+	if len(changes) == 0 {
+		return "", "", nil
+	}
+
+	version, err := nextMigrationVersion(defaultMigrationsDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	currentByTable := map[string][]schemaField{}
+	for _, st := range structs {
+		table := g.getTableName(st)
+		for _, f := range nonArrayFields(st) {
+			currentByTable[table] = append(currentByTable[table], schemaField{Name: f.Name, Type: f.Type})
+		}
+	}
+
+	slug := migrationSlug(changes)
+	name := fmt.Sprintf("%04d_%s", version, slug)
+
+	var upStmts, downStmts []string
+	for _, c := range changes {
+		up, down := g.alterStatements(c, currentByTable[c.Table])
+		upStmts = append(upStmts, up...)
+		downStmts = append(downStmts, down...)
+	}
+
+	source = g.renderMigrationSource(name, upStmts, downStmts)
+	filename = filepath.Join(defaultMigrationsDir, name+".c")
+
+	if err := os.MkdirAll(defaultMigrationsDir, 0o755); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(filename, []byte(source), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := saveSchema(schemaPath, snapshotSchema(structs)); err != nil {
+		return "", "", err
+	}
+
+	return filename, source, nil
+}
+
+// renderMigrationSource emits migration_<name>_up/_down, each running its
+// statements inside its own braced block so every ExecDDLSnippet's local
+// rc/err_msg declarations don't collide across statements.
+func (g *CGenerator) renderMigrationSource(name string, up, down []string) string {
+	var sb strings.Builder
+	sb.WriteString(g.db.Headers())
+	sb.WriteString(g.db.ExternConnDecl())
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("int migration_%s_up(void) {\n", name))
+	sb.WriteString(g.renderMigrationStatements(up))
+	sb.WriteString("    return 0;\n}\n\n")
+	sb.WriteString(fmt.Sprintf("int migration_%s_down(void) {\n", name))
+	sb.WriteString(g.renderMigrationStatements(down))
+	sb.WriteString("    return 0;\n}\n")
+	return sb.String()
+}
+
+// migrationFile is one parsed migrations/NNNN_slug.c entry.
+type migrationFile struct {
+	Version int
+	Slug    string
+}
+
+var migrationFileNamePattern = regexp.MustCompile(`^(\d{4})_(.+)\.c$`)
+
+// scanMigrations lists every migration file under dir, in version order.
+func scanMigrations(dir string) ([]migrationFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []migrationFile
+	for _, e := range entries {
+		m := migrationFileNamePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		files = append(files, migrationFile{Version: version, Slug: m[2]})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Version < files[j].Version })
+	return files, nil
+}
+
+// generateMigrationsRuntime emits the migrations tracking table plus a
+// run_migrations() function that applies every pending migration found
+// under defaultMigrationsDir, in order, each wrapped in its own
+// BEGIN/COMMIT (ROLLBACK on failure) so a migration's statements apply
+// atomically. Always emits a callable run_migrations() - even an empty one
+// - so generateMain/generateWebMain can call it unconditionally.
+func (g *CGenerator) generateMigrationsRuntime() {
+	files, err := scanMigrations(defaultMigrationsDir)
+	if err != nil || len(files) == 0 {
+		g.output.WriteString("void run_migrations() {}\n\n")
+		return
+	}
+
+	g.output.WriteString("// Schema migrations, one up/down pair per migrations/NNNN_*.c file.\n")
+	for _, f := range files {
+		full := fmt.Sprintf("%04d_%s", f.Version, f.Slug)
+		g.output.WriteString(fmt.Sprintf("extern int migration_%s_up(void);\n", full))
+		g.output.WriteString(fmt.Sprintf("extern int migration_%s_down(void);\n", full))
+	}
+	g.output.WriteString("\n")
+
+	g.output.WriteString("static int migration_applied(int64_t version) {\n")
+	g.output.WriteString(fmt.Sprintf("    char *sql = \"SELECT version FROM migrations WHERE version = %s\";\n", g.db.Placeholder(1)))
+	g.output.WriteString("    " + g.db.StmtVarDecl("mstmt", 1) + "\n")
+	g.output.WriteString("    " + g.db.PrepareSnippet("mstmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n        " + g.db.FinalizeSnippet("mstmt"))
+	g.output.WriteString("        return 0;\n    }\n")
+	if snippet := g.db.BindSnippet("mstmt", 0, "int64_t", "version"); snippet != "" {
+		g.output.WriteString("    " + snippet + "\n")
+	}
+	g.output.WriteString("    " + g.db.StepRowSnippet("mstmt") + "\n")
+	g.output.WriteString("    int applied = (rc == 1);\n")
+	g.output.WriteString("    " + g.db.FinalizeSnippet("mstmt"))
+	g.output.WriteString("    return applied;\n")
+	g.output.WriteString("}\n\n")
+
+	g.output.WriteString("static void migration_record(int64_t version, const char* name) {\n")
+	g.output.WriteString(fmt.Sprintf("    char *sql = \"INSERT INTO migrations (version, name) VALUES (%s, %s)\";\n",
+		g.db.Placeholder(1), g.db.Placeholder(2)))
+	g.output.WriteString("    " + g.db.StmtVarDecl("mstmt", 2) + "\n")
+	g.output.WriteString("    " + g.db.PrepareSnippet("mstmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n        " + g.db.FinalizeSnippet("mstmt"))
+	g.output.WriteString("        return;\n    }\n")
+	if snippet := g.db.BindSnippet("mstmt", 0, "int64_t", "version"); snippet != "" {
+		g.output.WriteString("    " + snippet + "\n")
+	}
+	if snippet := g.db.BindSnippet("mstmt", 1, "char*", "name"); snippet != "" {
+		g.output.WriteString("    " + snippet + "\n")
+	}
+	g.output.WriteString("    " + g.db.ExecSnippet("mstmt", 2) + "\n")
+	g.output.WriteString("    " + g.db.FinalizeSnippet("mstmt"))
+	g.output.WriteString("}\n\n")
+
+	g.output.WriteString("void run_migrations() {\n")
+	ddlSQL := `"CREATE TABLE IF NOT EXISTS migrations (version INTEGER PRIMARY KEY, name TEXT)"`
+	g.output.WriteString("    " + g.db.ExecDDLSnippet(ddlSQL) + "\n\n")
+
+	for _, f := range files {
+		full := fmt.Sprintf("%04d_%s", f.Version, f.Slug)
+		g.output.WriteString(fmt.Sprintf("    if (!migration_applied(%d)) {\n", f.Version))
+		g.output.WriteString("        {\n")
+		g.output.WriteString("        char *begin_sql = \"BEGIN\";\n")
+		g.output.WriteString("        " + g.db.ExecDDLSnippet("begin_sql") + "\n")
+		g.output.WriteString("        }\n")
+		g.output.WriteString(fmt.Sprintf("        if (migration_%s_up() == 0) {\n", full))
+		g.output.WriteString(fmt.Sprintf("            migration_record(%d, \"%s\");\n", f.Version, full))
+		g.output.WriteString("            {\n")
+		g.output.WriteString("            char *commit_sql = \"COMMIT\";\n")
+		g.output.WriteString("            " + g.db.ExecDDLSnippet("commit_sql") + "\n")
+		g.output.WriteString("            }\n")
+		g.output.WriteString(fmt.Sprintf("            printf(\"Applied migration %s\\n\");\n", full))
+		g.output.WriteString("        } else {\n")
+		g.output.WriteString("            {\n")
+		g.output.WriteString("            char *rollback_sql = \"ROLLBACK\";\n")
+		g.output.WriteString("            " + g.db.ExecDDLSnippet("rollback_sql") + "\n")
+		g.output.WriteString("            }\n")
+		g.output.WriteString(fmt.Sprintf("            fprintf(stderr, \"Migration %s failed\\n\");\n", full))
+		g.output.WriteString("        }\n")
+		g.output.WriteString("    }\n")
+	}
+	g.output.WriteString("}\n\n")
+}
+
+func (g *CGenerator) renderMigrationStatements(stmts []string) string {
+	var sb strings.Builder
+	for _, stmt := range stmts {
+		escaped := strings.ReplaceAll(stmt, `"`, `\"`)
+		sb.WriteString("    {\n")
+		sb.WriteString(fmt.Sprintf("        char *sql = \"%s\";\n", escaped))
+		sb.WriteString("        " + g.db.ExecDDLSnippet("sql") + "\n")
+		sb.WriteString("        if (rc != 0) return 1;\n")
+		sb.WriteString("    }\n")
+	}
+	return sb.String()
+}
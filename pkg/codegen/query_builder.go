@@ -0,0 +1,332 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// maxQueryParams bounds how many WHERE conditions a single generated query
+// builder can accumulate; all query structs/arrays below this size are
+// stack-free fixed allocations, not a hard DSL limit worth making dynamic.
+const maxQueryParams = 16
+
+// queryOperator describes one Django/Beego-style field lookup: the
+// generated function's suffix (Struct_where_field_<suffix>), the SQL
+// comparison operator it renders through query_op_sql, how (if at all) the
+// bound value needs wrapping for LIKE, and which field kinds it applies to.
+type queryOperator struct {
+	suffix   string
+	opEnum   string
+	likeWrap string // "", "contains", "prefix", "suffix"
+	caseFold bool
+	kinds    []string // "string", "int", "float", "bool"; nil = all kinds
+}
+
+var queryOperators = []queryOperator{
+	{suffix: "exact", opEnum: "QOP_EXACT"},
+	{suffix: "iexact", opEnum: "QOP_EXACT", caseFold: true, kinds: []string{"string"}},
+	{suffix: "contains", opEnum: "QOP_LIKE", likeWrap: "contains", kinds: []string{"string"}},
+	{suffix: "icontains", opEnum: "QOP_LIKE", likeWrap: "contains", caseFold: true, kinds: []string{"string"}},
+	{suffix: "gt", opEnum: "QOP_GT", kinds: []string{"int", "float"}},
+	{suffix: "gte", opEnum: "QOP_GTE", kinds: []string{"int", "float"}},
+	{suffix: "lt", opEnum: "QOP_LT", kinds: []string{"int", "float"}},
+	{suffix: "lte", opEnum: "QOP_LTE", kinds: []string{"int", "float"}},
+	{suffix: "startswith", opEnum: "QOP_LIKE", likeWrap: "prefix", kinds: []string{"string"}},
+	{suffix: "endswith", opEnum: "QOP_LIKE", likeWrap: "suffix", kinds: []string{"string"}},
+}
+
+func (op queryOperator) appliesTo(kind string) bool {
+	if op.kinds == nil {
+		return true
+	}
+	for _, k := range op.kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldKind buckets a field's zelang type into the lookup groups
+// queryOperators switches on.
+func fieldKind(field *ast.FieldDecl) string {
+	switch field.Type {
+	case "int":
+		return "int"
+	case "float":
+		return "float"
+	case "bool":
+		return "bool"
+	default:
+		return "string" // string, date, datetime
+	}
+}
+
+// generateQueryRuntime emits the query-builder plumbing shared by every
+// struct in the file: the bound-parameter tagged union and the SQL
+// comparison-operator table the request asked be emitted once per file,
+// plus any dialect-specific runtime helper (e.g. Postgres' $N placeholder).
+func (g *CGenerator) generateQueryRuntime() {
+	g.output.WriteString(`// Query builder support, shared by every Struct_query() below.
+typedef enum { QPARAM_INT, QPARAM_DOUBLE, QPARAM_STRING } QueryParamType;
+
+typedef struct {
+    QueryParamType type;
+    int64_t ival;
+    double dval;
+    char* sval;
+    int owned;
+} QueryParam;
+
+enum { QOP_EXACT, QOP_LIKE, QOP_GT, QOP_GTE, QOP_LT, QOP_LTE };
+static const char* query_op_sql[] = { "=", "LIKE", ">", ">=", "<", "<=" };
+
+`)
+	if helpers := g.db.RuntimeHelpers(); helpers != "" {
+		g.output.WriteString(helpers)
+	}
+}
+
+// generateQueryBuilder emits a chainable <Struct>Query builder: a
+// Struct_query() constructor, a Struct_where_<field>_<op>(...) function per
+// applicable lookup, Struct_order_by/_limit/_offset, and a terminal
+// Struct_query_exec that runs the assembled SELECT.
+func (g *CGenerator) generateQueryBuilder(s *ast.StructDecl, tableName string) {
+	name := s.Name
+	fields := nonArrayFields(s)
+
+	g.output.WriteString(fmt.Sprintf("// Query builder for %s\n", name))
+	g.output.WriteString(fmt.Sprintf(`typedef struct {
+    char* where_clauses[%d];
+    int clause_count;
+    QueryParam params[%d];
+    int param_count;
+    char* order_by_field;
+    char* order_by_dir;
+    int limit_val;
+    int offset_val;
+} %sQuery;
+
+%sQuery* %s_query() {
+    %sQuery* q = (%sQuery*)malloc(sizeof(%sQuery));
+    q->clause_count = 0;
+    q->param_count = 0;
+    q->order_by_field = NULL;
+    q->order_by_dir = NULL;
+    q->limit_val = -1;
+    q->offset_val = -1;
+    return q;
+}
+
+`, maxQueryParams, maxQueryParams, name, name, name, name, name, name))
+
+	for _, field := range fields {
+		g.generateWhereFuncs(name, field)
+	}
+
+	g.output.WriteString(fmt.Sprintf(`void %s_order_by(%sQuery* q, const char* field, const char* dir) {
+    q->order_by_field = strdup(field);
+    q->order_by_dir = strdup(dir);
+}
+
+void %s_limit(%sQuery* q, int n) {
+    q->limit_val = n;
+}
+
+void %s_offset(%sQuery* q, int n) {
+    q->offset_val = n;
+}
+
+`, name, name, name, name, name, name))
+
+	g.generateQueryExec(s, tableName, fields)
+}
+
+func (g *CGenerator) generateWhereFuncs(name string, field *ast.FieldDecl) {
+	kind := fieldKind(field)
+	cType := g.mapType(field.Type)
+
+	g.output.WriteString(fmt.Sprintf(`void %s_where_%s_isnull(%sQuery* q, int is_null) {
+    q->where_clauses[q->clause_count++] = is_null ? "%s IS NULL" : "%s IS NOT NULL";
+}
+
+`, name, field.Name, name, field.Name, field.Name))
+
+	if kind == "int" || kind == "string" {
+		g.generateWhereIn(name, field, kind)
+	}
+
+	for _, op := range queryOperators {
+		if !op.appliesTo(kind) {
+			continue
+		}
+
+		paramCType := cType
+		if paramCType == "char*" {
+			paramCType = "const char*"
+		}
+
+		colExpr := field.Name
+		format := colExpr + " %s %s"
+		if op.caseFold {
+			format = fmt.Sprintf("LOWER(%s) %%s LOWER(%%s)", colExpr)
+		}
+
+		g.output.WriteString(fmt.Sprintf("void %s_where_%s_%s(%sQuery* q, %s value) {\n", name, field.Name, op.suffix, name, paramCType))
+		g.output.WriteString("    int pidx = q->param_count;\n")
+		g.output.WriteString("    char clause[256];\n")
+		g.output.WriteString(fmt.Sprintf("    snprintf(clause, sizeof(clause), \"%s\", query_op_sql[%s], %s);\n",
+			format, op.opEnum, g.db.PlaceholderExpr("pidx")))
+		g.output.WriteString("    q->where_clauses[q->clause_count++] = strdup(clause);\n")
+
+		switch {
+		case kind == "string" && op.likeWrap != "":
+			g.output.WriteString("    char* escaped = (char*)malloc(strlen(value) + 3);\n")
+			switch op.likeWrap {
+			case "contains":
+				g.output.WriteString("    sprintf(escaped, \"%%%s%%\", value);\n")
+			case "prefix":
+				g.output.WriteString("    sprintf(escaped, \"%s%%\", value);\n")
+			case "suffix":
+				g.output.WriteString("    sprintf(escaped, \"%%%s\", value);\n")
+			}
+			g.output.WriteString("    q->params[pidx].type = QPARAM_STRING;\n")
+			g.output.WriteString("    q->params[pidx].sval = escaped;\n")
+			g.output.WriteString("    q->params[pidx].owned = 1;\n")
+		case kind == "string":
+			g.output.WriteString("    q->params[pidx].type = QPARAM_STRING;\n")
+			g.output.WriteString("    q->params[pidx].sval = strdup(value);\n")
+			g.output.WriteString("    q->params[pidx].owned = 1;\n")
+		case kind == "float":
+			g.output.WriteString("    q->params[pidx].type = QPARAM_DOUBLE;\n")
+			g.output.WriteString("    q->params[pidx].dval = value;\n")
+			g.output.WriteString("    q->params[pidx].owned = 0;\n")
+		default: // int, bool
+			g.output.WriteString("    q->params[pidx].type = QPARAM_INT;\n")
+			g.output.WriteString("    q->params[pidx].ival = value;\n")
+			g.output.WriteString("    q->params[pidx].owned = 0;\n")
+		}
+		g.output.WriteString("    q->param_count++;\n")
+		g.output.WriteString("}\n\n")
+	}
+}
+
+// generateWhereIn emits Struct_where_<field>_in, the one lookup that binds a
+// variable number of values behind a single WHERE clause.
+func (g *CGenerator) generateWhereIn(name string, field *ast.FieldDecl, kind string) {
+	valCType := "int64_t*"
+	if kind == "string" {
+		valCType = "const char**"
+	}
+
+	g.output.WriteString(fmt.Sprintf("void %s_where_%s_in(%sQuery* q, %s values, int n) {\n", name, field.Name, name, valCType))
+	g.output.WriteString("    char clause[256];\n")
+	g.output.WriteString(fmt.Sprintf("    int offset = snprintf(clause, sizeof(clause), \"%s IN (\");\n", field.Name))
+	g.output.WriteString("    for (int i = 0; i < n; i++) {\n")
+	g.output.WriteString("        int pidx = q->param_count + i;\n")
+	g.output.WriteString(fmt.Sprintf("        offset += snprintf(clause + offset, sizeof(clause) - offset, \"%%s%%s\", i > 0 ? \",\" : \"\", %s);\n",
+		g.db.PlaceholderExpr("pidx")))
+	g.output.WriteString("    }\n")
+	g.output.WriteString("    snprintf(clause + offset, sizeof(clause) - offset, \")\");\n")
+	g.output.WriteString("    q->where_clauses[q->clause_count++] = strdup(clause);\n")
+	g.output.WriteString("    for (int i = 0; i < n; i++) {\n")
+	if kind == "string" {
+		g.output.WriteString("        q->params[q->param_count + i].type = QPARAM_STRING;\n")
+		g.output.WriteString("        q->params[q->param_count + i].sval = strdup(values[i]);\n")
+		g.output.WriteString("        q->params[q->param_count + i].owned = 1;\n")
+	} else {
+		g.output.WriteString("        q->params[q->param_count + i].type = QPARAM_INT;\n")
+		g.output.WriteString("        q->params[q->param_count + i].ival = values[i];\n")
+		g.output.WriteString("        q->params[q->param_count + i].owned = 0;\n")
+	}
+	g.output.WriteString("    }\n")
+	g.output.WriteString("    q->param_count += n;\n")
+	g.output.WriteString("}\n\n")
+}
+
+// generateQueryExec emits the terminal call that assembles the SELECT from
+// the builder's accumulated state and runs it, following the same
+// prepare/bind/step/finalize shape as generateFind/generateAll.
+func (g *CGenerator) generateQueryExec(s *ast.StructDecl, tableName string, fields []*ast.FieldDecl) {
+	name := s.Name
+
+	g.output.WriteString(fmt.Sprintf("%s** %s_query_exec(%sQuery* q, int* count) {\n", name, name, name))
+	g.output.WriteString("    char sql[2048];\n")
+	g.output.WriteString(fmt.Sprintf("    int offset = snprintf(sql, sizeof(sql), \"SELECT * FROM %s\");\n", tableName))
+	g.output.WriteString("    if (q->clause_count > 0) {\n")
+	g.output.WriteString("        offset += snprintf(sql + offset, sizeof(sql) - offset, \" WHERE \");\n")
+	g.output.WriteString("        for (int i = 0; i < q->clause_count; i++) {\n")
+	g.output.WriteString("            offset += snprintf(sql + offset, sizeof(sql) - offset, \"%s%s\", i > 0 ? \" AND \" : \"\", q->where_clauses[i]);\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString("    }\n")
+	g.output.WriteString("    if (q->order_by_field) {\n")
+	g.output.WriteString("        offset += snprintf(sql + offset, sizeof(sql) - offset, \" ORDER BY %s %s\", q->order_by_field, q->order_by_dir);\n")
+	g.output.WriteString("    }\n")
+	g.output.WriteString("    if (q->limit_val >= 0) {\n")
+	g.output.WriteString("        offset += snprintf(sql + offset, sizeof(sql) - offset, \" LIMIT %d\", q->limit_val);\n")
+	g.output.WriteString("    }\n")
+	g.output.WriteString("    if (q->offset_val >= 0) {\n")
+	g.output.WriteString("        offset += snprintf(sql + offset, sizeof(sql) - offset, \" OFFSET %d\", q->offset_val);\n")
+	g.output.WriteString("    }\n\n")
+
+	g.output.WriteString("    " + g.db.StmtVarDecl("stmt", maxQueryParams) + "\n")
+	g.output.WriteString("    " + g.db.PrepareSnippet("stmt", "sql") + "\n")
+	g.output.WriteString("    if (rc != 0) {\n")
+	g.output.WriteString(fmt.Sprintf("        fprintf(stderr, \"Failed to prepare statement: %%s\\n\", %s);\n", g.db.ErrorExpr()))
+	g.output.WriteString("        *count = 0;\n")
+	g.output.WriteString("        return NULL;\n")
+	g.output.WriteString("    }\n\n")
+
+	g.output.WriteString("    for (int i = 0; i < q->param_count; i++) {\n")
+	g.output.WriteString("        switch (q->params[i].type) {\n")
+	g.output.WriteString("        case QPARAM_INT:\n")
+	g.output.WriteString("            " + g.db.BindDynamicSnippet("stmt", "i", "int64_t", "q->params[i].ival") + "\n")
+	g.output.WriteString("            break;\n")
+	g.output.WriteString("        case QPARAM_DOUBLE:\n")
+	g.output.WriteString("            " + g.db.BindDynamicSnippet("stmt", "i", "double", "q->params[i].dval") + "\n")
+	g.output.WriteString("            break;\n")
+	g.output.WriteString("        case QPARAM_STRING:\n")
+	g.output.WriteString("            " + g.db.BindDynamicSnippet("stmt", "i", "char*", "q->params[i].sval") + "\n")
+	g.output.WriteString("            break;\n")
+	g.output.WriteString("        }\n")
+	g.output.WriteString("    }\n\n")
+
+	if rv := g.db.ResultVarDecl("stmt", fields); rv != "" {
+		g.output.WriteString("    " + rv + "\n\n")
+	}
+
+	g.output.WriteString("    int capacity = 10;\n")
+	g.output.WriteString(fmt.Sprintf("    %s** results = (%s**)malloc(capacity * sizeof(%s*));\n", name, name, name))
+	g.output.WriteString("    int n = 0;\n\n")
+
+	g.output.WriteString("    " + g.db.StepRowSnippet("stmt") + "\n")
+	g.output.WriteString("    while (rc == 1) {\n")
+	g.output.WriteString("        if (n >= capacity) {\n")
+	g.output.WriteString("            capacity *= 2;\n")
+	g.output.WriteString(fmt.Sprintf("            results = (%s**)realloc(results, capacity * sizeof(%s*));\n", name, name))
+	g.output.WriteString("        }\n\n")
+
+	g.output.WriteString(fmt.Sprintf("        %s* obj = (%s*)malloc(sizeof(%s));\n", name, name, name))
+	for colIndex, field := range fields {
+		g.output.WriteString(fmt.Sprintf("        obj->%s = %s;\n", field.Name, g.db.ColumnReadSnippet("stmt", field, colIndex)))
+	}
+	g.output.WriteString("\n        results[n++] = obj;\n")
+	if adv := g.db.AdvanceRowSnippet("stmt"); adv != "" {
+		g.output.WriteString("        " + adv)
+	}
+	g.output.WriteString("        " + g.db.StepRowSnippet("stmt") + "\n")
+	g.output.WriteString("    }\n\n")
+
+	g.output.WriteString("    " + g.db.FinalizeSnippet("stmt"))
+
+	g.output.WriteString("    for (int i = 0; i < q->clause_count; i++) free(q->where_clauses[i]);\n")
+	g.output.WriteString("    for (int i = 0; i < q->param_count; i++) if (q->params[i].owned) free(q->params[i].sval);\n")
+	g.output.WriteString("    if (q->order_by_field) free(q->order_by_field);\n")
+	g.output.WriteString("    if (q->order_by_dir) free(q->order_by_dir);\n")
+	g.output.WriteString("    free(q);\n\n")
+
+	g.output.WriteString("    *count = n;\n")
+	g.output.WriteString("    return results;\n")
+	g.output.WriteString("}\n\n")
+}
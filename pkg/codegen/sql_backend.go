@@ -0,0 +1,157 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// DefaultSQLBackend is the dialect CGenerator targets when a program
+// declares no @database decorator.
+const DefaultSQLBackend = "sqlite"
+
+// SQLBackend abstracts the C client-library calls CGenerator emits for
+// schema creation and CRUD, so the same struct/HTML/route-handler code can
+// target SQLite, MySQL, or PostgreSQL instead of hard-coding libsqlite3.
+// Mirrors the seam Beego's ORM uses for multi-dialect support: each dialect
+// owns its own type map, placeholder syntax, autoincrement spelling, and
+// last-insert-id mechanism, while the generator methods only ever call
+// through this interface.
+//
+// Every Prepare/Exec/StepRow snippet normalizes its dialect's result code
+// into the caller's existing "rc" variable: 0 means success (or, for
+// StepRow, "no row left"), 1 means failure (or "row available"), so
+// generateCreate/generateFind/generateAll/generateDelete can branch on rc
+// identically regardless of dialect.
+type SQLBackend interface {
+	// Name identifies the dialect, e.g. "sqlite"; also the string
+	// decorator.Constraints dialect-switches its autoincrement spelling on.
+	Name() string
+
+	// Headers returns the #include line this dialect's client library needs.
+	Headers() string
+	// GlobalVarDecl declares the global connection handle.
+	GlobalVarDecl() string
+	// OpenSnippet opens dsn into the global connection handle, returning 1
+	// from the caller's function on failure.
+	OpenSnippet(dsn string) string
+	// CloseSnippet closes the global connection handle.
+	CloseSnippet() string
+	// ErrorExpr is the C expression yielding the connection's last error string.
+	ErrorExpr() string
+
+	// MapSQLType maps a zelang field type to this dialect's column type.
+	// autoIncrement is true for a field that is both @primary and
+	// @autoincrement, since that combination picks the column type itself
+	// on some dialects (e.g. Postgres' BIGSERIAL).
+	MapSQLType(zlType string, autoIncrement bool) string
+	// Placeholder returns the parameter marker for the i-th (1-based) bound
+	// value, e.g. "?" for sqlite/mysql, "$1" for postgres.
+	Placeholder(i int) string
+	// InsertReturningClause is appended to an INSERT statement's SQL text
+	// when this dialect needs it to recover the new row's ID (Postgres'
+	// "RETURNING id"); "" for dialects that use a separate last-insert-id call.
+	InsertReturningClause() string
+
+	// ExecDDLSnippet runs a parameter-less DDL statement (CREATE TABLE),
+	// assigning 0 to rc on success and nonzero on failure, printing this
+	// dialect's error message on failure.
+	ExecDDLSnippet(sqlVar string) string
+
+	// StmtVarDecl declares whatever per-statement state this dialect needs
+	// (a prepared-statement handle, a bind-parameter array, ...) for a
+	// statement with bindCount bound input parameters.
+	StmtVarDecl(stmtVar string, bindCount int) string
+	// PrepareSnippet prepares sqlVar's text into stmtVar, assigning 0 to rc
+	// on success and nonzero on failure.
+	PrepareSnippet(stmtVar, sqlVar string) string
+	// BindSnippet binds value (already a C expression, e.g. a variable
+	// name) at the 0-based parameter index.
+	BindSnippet(stmtVar string, index int, cType, value string) string
+	// ExecSnippet runs an INSERT/DELETE statement built from bindCount
+	// bound parameters, assigning 0 to rc on success and nonzero on failure.
+	ExecSnippet(stmtVar string, bindCount int) string
+
+	// ResultVarDecl declares and binds whatever per-row output state this
+	// dialect needs to read fields back (e.g. MySQL's MYSQL_BIND result
+	// array), ahead of the fetch loop in a SELECT. "" for dialects that
+	// read columns directly off the statement/result handle instead.
+	ResultVarDecl(stmtVar string, fields []*ast.FieldDecl) string
+	// StepRowSnippet advances a SELECT by one row, assigning 1 to rc when a
+	// row is available and 0 once exhausted.
+	StepRowSnippet(stmtVar string) string
+	// AdvanceRowSnippet moves to the next row after its columns have been
+	// read; "" for dialects whose StepRowSnippet already advances.
+	AdvanceRowSnippet(stmtVar string) string
+	// ColumnReadSnippet is a C expression reading field's value out of the
+	// current row.
+	ColumnReadSnippet(stmtVar string, field *ast.FieldDecl, colIndex int) string
+
+	// LastInsertIDSnippet is a C expression yielding the row ID the most
+	// recent ExecSnippet insert produced.
+	LastInsertIDSnippet(stmtVar string) string
+	// FinalizeSnippet releases stmtVar.
+	FinalizeSnippet(stmtVar string) string
+
+	// RuntimeHelpers returns extra C declarations this dialect needs once
+	// per generated file to support a dynamic number of bound parameters
+	// (the query builder's WHERE clause count isn't known until it runs);
+	// "" for dialects whose placeholder syntax doesn't depend on position.
+	RuntimeHelpers() string
+	// PlaceholderExpr is a C expression (evaluating to const char*) for the
+	// placeholder at the 0-based position named by the indexVar C
+	// expression, e.g. "q->param_count" or a loop variable - used where the
+	// bind count is only known at runtime, unlike Placeholder's Go int.
+	PlaceholderExpr(indexVar string) string
+	// BindDynamicSnippet is BindSnippet for a parameter index that is only
+	// known at runtime (a C expression, not a Go int), as the query
+	// builder's WHERE clauses accumulate.
+	BindDynamicSnippet(stmtVar, indexVar, cType, value string) string
+
+	// ExternConnDecl declares the global connection handle GlobalVarDecl
+	// defines, for standalone translation units (e.g. a migration file)
+	// that need to reference it without redefining it.
+	ExternConnDecl() string
+}
+
+// newSQLBackend returns the SQLBackend registered under name.
+func newSQLBackend(name string) (SQLBackend, error) {
+	switch name {
+	case "", "sqlite":
+		return sqliteSQLBackend{}, nil
+	case "mysql":
+		return mysqlSQLBackend{}, nil
+	case "postgres", "postgresql":
+		return postgresSQLBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown database backend %q", name)
+	}
+}
+
+// defaultDSN is the connection string CGenerator uses when a program (or its
+// @database decorator) doesn't supply one explicitly.
+func defaultDSN(dialect string) string {
+	switch dialect {
+	case "mysql", "postgres", "postgresql":
+		return "app"
+	default:
+		return "app.db"
+	}
+}
+
+// cTypeOf mirrors CGenerator.mapType for a field's host type, since several
+// SQLBackend methods key their snippets off it.
+func cTypeOf(field *ast.FieldDecl) string {
+	switch field.Type {
+	case "int":
+		return "int64_t"
+	case "float":
+		return "double"
+	case "string", "date", "datetime", "file", "blob":
+		return "char*"
+	case "bool":
+		return "int"
+	default:
+		return field.Type
+	}
+}
@@ -0,0 +1,167 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// mysqlSQLBackend targets libmysqlclient. Mutations (create/delete) go
+// through a real MYSQL_STMT with an input MYSQL_BIND array; reads (find/all)
+// bind result columns the same way ahead of the fetch loop, following the
+// libmysqlclient prepared-statement API throughout.
+type mysqlSQLBackend struct{}
+
+func (mysqlSQLBackend) Name() string { return "mysql" }
+
+func (mysqlSQLBackend) Headers() string { return "#include <mysql/mysql.h>\n" }
+
+func (mysqlSQLBackend) GlobalVarDecl() string { return "MYSQL *conn = NULL;\n" }
+
+func (mysqlSQLBackend) ExternConnDecl() string { return "extern MYSQL *conn;\n" }
+
+func (mysqlSQLBackend) OpenSnippet(dsn string) string {
+	return fmt.Sprintf(`conn = mysql_init(NULL);
+    if (conn == NULL || mysql_real_connect(conn, "localhost", "root", "", "%s", 0, NULL, 0) == NULL) {
+        fprintf(stderr, "Cannot open database: %%s\n", mysql_error(conn));
+        return 1;
+    }`, dsn)
+}
+
+func (mysqlSQLBackend) CloseSnippet() string { return "mysql_close(conn);\n" }
+
+func (mysqlSQLBackend) ErrorExpr() string { return "mysql_error(conn)" }
+
+func (mysqlSQLBackend) MapSQLType(zlType string, autoIncrement bool) string {
+	switch zlType {
+	case "int":
+		return "BIGINT"
+	case "float":
+		return "DOUBLE"
+	case "string":
+		return "TEXT"
+	case "bool":
+		return "TINYINT"
+	case "date", "datetime":
+		return "DATETIME"
+	default:
+		return "TEXT"
+	}
+}
+
+func (mysqlSQLBackend) Placeholder(int) string { return "?" }
+
+func (mysqlSQLBackend) InsertReturningClause() string { return "" }
+
+func (mysqlSQLBackend) ExecDDLSnippet(sqlVar string) string {
+	return fmt.Sprintf(`int rc = mysql_query(conn, %s);
+    if (rc != 0) {
+        fprintf(stderr, "SQL error: %%s\n", mysql_error(conn));
+    }`, sqlVar)
+}
+
+func (mysqlSQLBackend) StmtVarDecl(stmtVar string, bindCount int) string {
+	out := fmt.Sprintf("MYSQL_STMT *%s = mysql_stmt_init(conn);\n", stmtVar)
+	if bindCount > 0 {
+		out += fmt.Sprintf("    MYSQL_BIND %s_bind[%d];\n", stmtVar, bindCount)
+		out += fmt.Sprintf("    memset(%s_bind, 0, sizeof(%s_bind));\n", stmtVar, stmtVar)
+	}
+	return out
+}
+
+func (mysqlSQLBackend) PrepareSnippet(stmtVar, sqlVar string) string {
+	return fmt.Sprintf("rc = mysql_stmt_prepare(%s, %s, strlen(%s));", stmtVar, sqlVar, sqlVar)
+}
+
+func (mysqlSQLBackend) BindSnippet(stmtVar string, index int, cType, value string) string {
+	switch cType {
+	case "int64_t":
+		return fmt.Sprintf(`%s_bind[%d].buffer_type = MYSQL_TYPE_LONGLONG;
+    %s_bind[%d].buffer = &%s;`, stmtVar, index, stmtVar, index, value)
+	case "double":
+		return fmt.Sprintf(`%s_bind[%d].buffer_type = MYSQL_TYPE_DOUBLE;
+    %s_bind[%d].buffer = &%s;`, stmtVar, index, stmtVar, index, value)
+	case "char*":
+		return fmt.Sprintf(`%s_bind[%d].buffer_type = MYSQL_TYPE_STRING;
+    %s_bind[%d].buffer = %s;
+    %s_bind[%d].buffer_length = strlen(%s);`, stmtVar, index, stmtVar, index, value, stmtVar, index, value)
+	default:
+		return ""
+	}
+}
+
+func (mysqlSQLBackend) ExecSnippet(stmtVar string, bindCount int) string {
+	var out string
+	if bindCount > 0 {
+		out = fmt.Sprintf("mysql_stmt_bind_param(%s, %s_bind);\n    ", stmtVar, stmtVar)
+	}
+	return out + fmt.Sprintf("rc = mysql_stmt_execute(%s);", stmtVar)
+}
+
+func (mysqlSQLBackend) ResultVarDecl(stmtVar string, fields []*ast.FieldDecl) string {
+	out := fmt.Sprintf("MYSQL_BIND %s_result[%d];\n", stmtVar, len(fields))
+	out += fmt.Sprintf("    memset(%s_result, 0, sizeof(%s_result));\n", stmtVar, stmtVar)
+	for i, field := range fields {
+		switch cTypeOf(field) {
+		case "char*":
+			out += fmt.Sprintf("    char %s_buf_%s[256];\n", stmtVar, field.Name)
+			out += fmt.Sprintf("    %s_result[%d].buffer_type = MYSQL_TYPE_STRING;\n", stmtVar, i)
+			out += fmt.Sprintf("    %s_result[%d].buffer = %s_buf_%s;\n", stmtVar, i, stmtVar, field.Name)
+			out += fmt.Sprintf("    %s_result[%d].buffer_length = sizeof(%s_buf_%s);\n", stmtVar, i, stmtVar, field.Name)
+		case "double":
+			out += fmt.Sprintf("    double %s_val_%s = 0;\n", stmtVar, field.Name)
+			out += fmt.Sprintf("    %s_result[%d].buffer_type = MYSQL_TYPE_DOUBLE;\n", stmtVar, i)
+			out += fmt.Sprintf("    %s_result[%d].buffer = &%s_val_%s;\n", stmtVar, i, stmtVar, field.Name)
+		default:
+			out += fmt.Sprintf("    int64_t %s_val_%s = 0;\n", stmtVar, field.Name)
+			out += fmt.Sprintf("    %s_result[%d].buffer_type = MYSQL_TYPE_LONGLONG;\n", stmtVar, i)
+			out += fmt.Sprintf("    %s_result[%d].buffer = &%s_val_%s;\n", stmtVar, i, stmtVar, field.Name)
+		}
+	}
+	out += fmt.Sprintf("    mysql_stmt_bind_result(%s, %s_result);\n", stmtVar, stmtVar)
+	out += fmt.Sprintf("    mysql_stmt_store_result(%s);", stmtVar)
+	return out
+}
+
+func (mysqlSQLBackend) StepRowSnippet(stmtVar string) string {
+	return fmt.Sprintf(`rc = mysql_stmt_fetch(%s);
+    rc = (rc == 0) ? 1 : 0;`, stmtVar)
+}
+
+func (mysqlSQLBackend) AdvanceRowSnippet(stmtVar string) string { return "" }
+
+func (mysqlSQLBackend) ColumnReadSnippet(stmtVar string, field *ast.FieldDecl, colIndex int) string {
+	if cTypeOf(field) == "char*" {
+		return fmt.Sprintf("strdup(%s_buf_%s)", stmtVar, field.Name)
+	}
+	return fmt.Sprintf("%s_val_%s", stmtVar, field.Name)
+}
+
+func (mysqlSQLBackend) LastInsertIDSnippet(stmtVar string) string {
+	return fmt.Sprintf("mysql_stmt_insert_id(%s)", stmtVar)
+}
+
+func (mysqlSQLBackend) FinalizeSnippet(stmtVar string) string {
+	return fmt.Sprintf("mysql_stmt_close(%s);\n", stmtVar)
+}
+
+func (mysqlSQLBackend) RuntimeHelpers() string { return "" }
+
+func (mysqlSQLBackend) PlaceholderExpr(indexVar string) string { return `"?"` }
+
+func (mysqlSQLBackend) BindDynamicSnippet(stmtVar, indexVar, cType, value string) string {
+	switch cType {
+	case "int64_t":
+		return fmt.Sprintf(`%s_bind[%s].buffer_type = MYSQL_TYPE_LONGLONG;
+    %s_bind[%s].buffer = &%s;`, stmtVar, indexVar, stmtVar, indexVar, value)
+	case "double":
+		return fmt.Sprintf(`%s_bind[%s].buffer_type = MYSQL_TYPE_DOUBLE;
+    %s_bind[%s].buffer = &%s;`, stmtVar, indexVar, stmtVar, indexVar, value)
+	case "char*":
+		return fmt.Sprintf(`%s_bind[%s].buffer_type = MYSQL_TYPE_STRING;
+    %s_bind[%s].buffer = %s;
+    %s_bind[%s].buffer_length = strlen(%s);`, stmtVar, indexVar, stmtVar, indexVar, value, stmtVar, indexVar, value)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,168 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// postgresSQLBackend targets libpq. There's no separate prepare step: each
+// statement is a single PQexecParams call against $1.."$N" placeholders, with
+// bound values staged into a paramValues array beforehand. Row iteration
+// walks PQgetvalue by an index this backend tracks itself (there is no
+// per-row "step" call in libpq's synchronous API), and a new row's ID comes
+// back via INSERT ... RETURNING id rather than a separate last-insert-id call.
+type postgresSQLBackend struct{}
+
+func (postgresSQLBackend) Name() string { return "postgres" }
+
+func (postgresSQLBackend) Headers() string { return "#include <libpq-fe.h>\n" }
+
+func (postgresSQLBackend) GlobalVarDecl() string { return "PGconn *conn = NULL;\n" }
+
+func (postgresSQLBackend) ExternConnDecl() string { return "extern PGconn *conn;\n" }
+
+func (postgresSQLBackend) OpenSnippet(dsn string) string {
+	return fmt.Sprintf(`conn = PQconnectdb("%s");
+    if (PQstatus(conn) != CONNECTION_OK) {
+        fprintf(stderr, "Cannot open database: %%s\n", PQerrorMessage(conn));
+        return 1;
+    }`, dsn)
+}
+
+func (postgresSQLBackend) CloseSnippet() string { return "PQfinish(conn);\n" }
+
+func (postgresSQLBackend) ErrorExpr() string { return "PQerrorMessage(conn)" }
+
+func (postgresSQLBackend) MapSQLType(zlType string, autoIncrement bool) string {
+	if autoIncrement {
+		return "BIGSERIAL"
+	}
+	switch zlType {
+	case "int":
+		return "BIGINT"
+	case "float":
+		return "DOUBLE PRECISION"
+	case "string":
+		return "TEXT"
+	case "bool":
+		return "BOOLEAN"
+	case "date", "datetime":
+		return "TIMESTAMP"
+	default:
+		return "TEXT"
+	}
+}
+
+func (postgresSQLBackend) Placeholder(i int) string { return fmt.Sprintf("$%d", i) }
+
+func (postgresSQLBackend) InsertReturningClause() string { return " RETURNING id" }
+
+func (postgresSQLBackend) ExecDDLSnippet(sqlVar string) string {
+	return fmt.Sprintf(`PGresult *ddl_res = PQexec(conn, %s);
+    int rc = (PQresultStatus(ddl_res) == PGRES_COMMAND_OK) ? 0 : 1;
+    if (rc != 0) {
+        fprintf(stderr, "SQL error: %%s\n", PQerrorMessage(conn));
+    }
+    PQclear(ddl_res);`, sqlVar)
+}
+
+func (postgresSQLBackend) StmtVarDecl(stmtVar string, bindCount int) string {
+	out := fmt.Sprintf("PGresult *%s = NULL;\n", stmtVar)
+	out += fmt.Sprintf("    int %s_row = 0;\n", stmtVar)
+	if bindCount > 0 {
+		out += fmt.Sprintf("    const char* %s_params[%d];\n", stmtVar, bindCount)
+	}
+	return out
+}
+
+func (postgresSQLBackend) PrepareSnippet(stmtVar, sqlVar string) string {
+	// libpq has no separate prepare step: PQexecParams both prepares and
+	// runs the statement, so this phase trivially succeeds.
+	return "rc = 0;"
+}
+
+func (postgresSQLBackend) BindSnippet(stmtVar string, index int, cType, value string) string {
+	switch cType {
+	case "char*":
+		return fmt.Sprintf("%s_params[%d] = %s;", stmtVar, index, value)
+	case "double":
+		return fmt.Sprintf(`char %s_buf%d[32];
+    snprintf(%s_buf%d, sizeof(%s_buf%d), "%%f", %s);
+    %s_params[%d] = %s_buf%d;`, stmtVar, index, stmtVar, index, stmtVar, index, value, stmtVar, index, stmtVar, index)
+	default:
+		return fmt.Sprintf(`char %s_buf%d[32];
+    snprintf(%s_buf%d, sizeof(%s_buf%d), "%%lld", (long long)%s);
+    %s_params[%d] = %s_buf%d;`, stmtVar, index, stmtVar, index, stmtVar, index, value, stmtVar, index, stmtVar, index)
+	}
+}
+
+func (postgresSQLBackend) ExecSnippet(stmtVar string, bindCount int) string {
+	paramsVar := "NULL"
+	if bindCount > 0 {
+		paramsVar = fmt.Sprintf("%s_params", stmtVar)
+	}
+	return fmt.Sprintf(`%s = PQexecParams(conn, sql, %d, NULL, %s, NULL, NULL, 0);
+    rc = (PQresultStatus(%s) == PGRES_COMMAND_OK || PQresultStatus(%s) == PGRES_TUPLES_OK) ? 0 : 1;`,
+		stmtVar, bindCount, paramsVar, stmtVar, stmtVar)
+}
+
+func (postgresSQLBackend) ResultVarDecl(stmtVar string, fields []*ast.FieldDecl) string { return "" }
+
+func (postgresSQLBackend) StepRowSnippet(stmtVar string) string {
+	return fmt.Sprintf("rc = (%s_row < PQntuples(%s)) ? 1 : 0;", stmtVar, stmtVar)
+}
+
+func (postgresSQLBackend) AdvanceRowSnippet(stmtVar string) string {
+	return fmt.Sprintf("%s_row++;\n", stmtVar)
+}
+
+func (postgresSQLBackend) ColumnReadSnippet(stmtVar string, field *ast.FieldDecl, colIndex int) string {
+	value := fmt.Sprintf("PQgetvalue(%s, %s_row, %d)", stmtVar, stmtVar, colIndex)
+	switch cTypeOf(field) {
+	case "char*":
+		return fmt.Sprintf("strdup(%s)", value)
+	case "double":
+		return fmt.Sprintf("atof(%s)", value)
+	default:
+		return fmt.Sprintf("atoll(%s)", value)
+	}
+}
+
+func (postgresSQLBackend) LastInsertIDSnippet(stmtVar string) string {
+	return fmt.Sprintf("atoll(PQgetvalue(%s, 0, 0))", stmtVar)
+}
+
+func (postgresSQLBackend) FinalizeSnippet(stmtVar string) string {
+	return fmt.Sprintf("PQclear(%s);\n", stmtVar)
+}
+
+// pgDynamicPlaceholderHelper renders the i-th (0-based) "$N" parameter
+// marker at runtime, for a WHERE clause whose final parameter count isn't
+// known until the query builder runs.
+const pgDynamicPlaceholderHelper = `static const char* pg_dynamic_placeholder(int idx) {
+    static char bufs[16][8];
+    snprintf(bufs[idx % 16], sizeof(bufs[idx % 16]), "$%d", idx + 1);
+    return bufs[idx % 16];
+}
+
+`
+
+func (postgresSQLBackend) RuntimeHelpers() string { return pgDynamicPlaceholderHelper }
+
+func (postgresSQLBackend) PlaceholderExpr(indexVar string) string {
+	return fmt.Sprintf("pg_dynamic_placeholder(%s)", indexVar)
+}
+
+func (postgresSQLBackend) BindDynamicSnippet(stmtVar, indexVar, cType, value string) string {
+	switch cType {
+	case "char*":
+		return fmt.Sprintf("%s_params[%s] = %s;", stmtVar, indexVar, value)
+	case "double":
+		return fmt.Sprintf(`%s_params[%s] = (char*)malloc(32);
+    snprintf((char*)%s_params[%s], 32, "%%f", %s);`, stmtVar, indexVar, stmtVar, indexVar, value)
+	default:
+		return fmt.Sprintf(`%s_params[%s] = (char*)malloc(32);
+    snprintf((char*)%s_params[%s], 32, "%%lld", (long long)%s);`, stmtVar, indexVar, stmtVar, indexVar, value)
+	}
+}
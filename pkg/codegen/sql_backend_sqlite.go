@@ -0,0 +1,135 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// sqliteSQLBackend targets libsqlite3, the original (and default) dialect
+// CGenerator supported before @database made the target pluggable.
+type sqliteSQLBackend struct{}
+
+func (sqliteSQLBackend) Name() string { return "sqlite" }
+
+func (sqliteSQLBackend) Headers() string { return "#include <sqlite3.h>\n" }
+
+func (sqliteSQLBackend) GlobalVarDecl() string { return "sqlite3 *db = NULL;\n" }
+
+func (sqliteSQLBackend) ExternConnDecl() string { return "extern sqlite3 *db;\n" }
+
+func (sqliteSQLBackend) OpenSnippet(dsn string) string {
+	return fmt.Sprintf(`int rc = sqlite3_open("%s", &db);
+    if (rc != SQLITE_OK) {
+        fprintf(stderr, "Cannot open database: %%s\n", sqlite3_errmsg(db));
+        return 1;
+    }`, dsn)
+}
+
+func (sqliteSQLBackend) CloseSnippet() string { return "sqlite3_close(db);\n" }
+
+func (sqliteSQLBackend) ErrorExpr() string { return "sqlite3_errmsg(db)" }
+
+func (sqliteSQLBackend) MapSQLType(zlType string, autoIncrement bool) string {
+	switch zlType {
+	case "int":
+		return "INTEGER"
+	case "float":
+		return "REAL"
+	case "string":
+		return "TEXT"
+	case "bool":
+		return "INTEGER"
+	case "date", "datetime":
+		return "TEXT"
+	default:
+		return "TEXT"
+	}
+}
+
+func (sqliteSQLBackend) Placeholder(int) string { return "?" }
+
+func (sqliteSQLBackend) InsertReturningClause() string { return "" }
+
+func (sqliteSQLBackend) ExecDDLSnippet(sqlVar string) string {
+	return fmt.Sprintf(`char *err_msg = NULL;
+    int rc = sqlite3_exec(db, %s, NULL, NULL, &err_msg);
+    if (rc != SQLITE_OK) {
+        fprintf(stderr, "SQL error: %%s\n", err_msg);
+        sqlite3_free(err_msg);
+    }`, sqlVar)
+}
+
+func (sqliteSQLBackend) StmtVarDecl(stmtVar string, bindCount int) string {
+	return fmt.Sprintf("sqlite3_stmt *%s;\n", stmtVar)
+}
+
+func (sqliteSQLBackend) PrepareSnippet(stmtVar, sqlVar string) string {
+	return fmt.Sprintf("rc = sqlite3_prepare_v2(db, %s, -1, &%s, NULL);", sqlVar, stmtVar)
+}
+
+func (sqliteSQLBackend) BindSnippet(stmtVar string, index int, cType, value string) string {
+	n := index + 1
+	switch cType {
+	case "int64_t":
+		return fmt.Sprintf("sqlite3_bind_int64(%s, %d, %s);", stmtVar, n, value)
+	case "double":
+		return fmt.Sprintf("sqlite3_bind_double(%s, %d, %s);", stmtVar, n, value)
+	case "char*":
+		return fmt.Sprintf("sqlite3_bind_text(%s, %d, %s, -1, SQLITE_TRANSIENT);", stmtVar, n, value)
+	default:
+		return ""
+	}
+}
+
+func (sqliteSQLBackend) ExecSnippet(stmtVar string, bindCount int) string {
+	return fmt.Sprintf(`rc = sqlite3_step(%s);
+    rc = (rc == SQLITE_DONE) ? 0 : 1;`, stmtVar)
+}
+
+func (sqliteSQLBackend) ResultVarDecl(stmtVar string, fields []*ast.FieldDecl) string { return "" }
+
+func (sqliteSQLBackend) StepRowSnippet(stmtVar string) string {
+	return fmt.Sprintf(`rc = sqlite3_step(%s);
+    rc = (rc == SQLITE_ROW) ? 1 : 0;`, stmtVar)
+}
+
+func (sqliteSQLBackend) AdvanceRowSnippet(stmtVar string) string { return "" }
+
+func (sqliteSQLBackend) ColumnReadSnippet(stmtVar string, field *ast.FieldDecl, colIndex int) string {
+	switch cTypeOf(field) {
+	case "int64_t":
+		return fmt.Sprintf("sqlite3_column_int64(%s, %d)", stmtVar, colIndex)
+	case "double":
+		return fmt.Sprintf("sqlite3_column_double(%s, %d)", stmtVar, colIndex)
+	case "char*":
+		return fmt.Sprintf("strdup((const char*)sqlite3_column_text(%s, %d))", stmtVar, colIndex)
+	default:
+		return fmt.Sprintf("sqlite3_column_int64(%s, %d)", stmtVar, colIndex)
+	}
+}
+
+func (sqliteSQLBackend) LastInsertIDSnippet(stmtVar string) string {
+	return "sqlite3_last_insert_rowid(db)"
+}
+
+func (sqliteSQLBackend) FinalizeSnippet(stmtVar string) string {
+	return fmt.Sprintf("sqlite3_finalize(%s);\n", stmtVar)
+}
+
+func (sqliteSQLBackend) RuntimeHelpers() string { return "" }
+
+func (sqliteSQLBackend) PlaceholderExpr(indexVar string) string { return `"?"` }
+
+func (sqliteSQLBackend) BindDynamicSnippet(stmtVar, indexVar, cType, value string) string {
+	switch cType {
+	case "int64_t":
+		return fmt.Sprintf("sqlite3_bind_int64(%s, (%s)+1, %s);", stmtVar, indexVar, value)
+	case "double":
+		return fmt.Sprintf("sqlite3_bind_double(%s, (%s)+1, %s);", stmtVar, indexVar, value)
+	case "char*":
+		return fmt.Sprintf("sqlite3_bind_text(%s, (%s)+1, %s, -1, SQLITE_TRANSIENT);", stmtVar, indexVar, value)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,317 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// RenderStatements compiles a function/handler body - built from the
+// statement and expression AST nodes the parser now produces - into C
+// statements. It's deliberately standalone rather than threaded through
+// TemplateGenerator/Backend: HandlerDecl/FunctionDecl bodies aren't wired
+// into the templated CRUD pipeline (g.handlers in template_generator.go is
+// collected but never rendered, and http_handler.tmpl only emits the fixed
+// page CRUD routes), so callers that want body-level C output call this
+// directly instead.
+func RenderStatements(body []ast.Node) (string, error) {
+	var out bytes.Buffer
+	for _, stmt := range body {
+		rendered, err := renderStmt(stmt)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(rendered)
+	}
+	return out.String(), nil
+}
+
+func renderStmt(node ast.Node) (string, error) {
+	switch s := node.(type) {
+	case *ast.IfStmt:
+		return renderIfStmt(s)
+	case *ast.ForStmt:
+		return renderForStmt(s)
+	case *ast.WhileStmt:
+		return renderWhileStmt(s)
+	case *ast.ReturnStmt:
+		return renderReturnStmt(s)
+	case *ast.BreakStmt:
+		return "break;\n", nil
+	case *ast.TypedDeclStmt:
+		return renderTypedDeclStmt(s)
+	case *ast.InferredDeclStmt:
+		return renderInferredDeclStmt(s)
+	case *ast.AssignStmt:
+		target, err := renderExpr(s.Target)
+		if err != nil {
+			return "", err
+		}
+		value, err := renderExpr(s.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s = %s;\n", target, value), nil
+	case *ast.FuncCallStmt:
+		call, err := renderExpr(s.Call)
+		if err != nil {
+			return "", err
+		}
+		return call + ";\n", nil
+	case *ast.EmptyStmt:
+		return ";\n", nil
+	default:
+		return "", fmt.Errorf("codegen: no C rendering for statement %T", node)
+	}
+}
+
+func renderIfStmt(s *ast.IfStmt) (string, error) {
+	cond, err := renderExpr(s.Cond)
+	if err != nil {
+		return "", err
+	}
+	body, err := RenderStatements(s.Body)
+	if err != nil {
+		return "", err
+	}
+	out := fmt.Sprintf("if (%s) {\n%s}\n", cond, indentBlock(body))
+
+	switch {
+	case len(s.Else) == 0:
+		return out, nil
+	case len(s.Else) == 1:
+		if elseIf, ok := s.Else[0].(*ast.IfStmt); ok {
+			rendered, err := renderIfStmt(elseIf)
+			if err != nil {
+				return "", err
+			}
+			return out + "else " + rendered, nil
+		}
+		fallthrough
+	default:
+		elseBody, err := RenderStatements(s.Else)
+		if err != nil {
+			return "", err
+		}
+		return out + fmt.Sprintf("else {\n%s}\n", indentBlock(elseBody)), nil
+	}
+}
+
+func renderForStmt(s *ast.ForStmt) (string, error) {
+	init, err := renderForClause(s.Init)
+	if err != nil {
+		return "", err
+	}
+
+	var cond string
+	if s.Cond != nil {
+		cond, err = renderExpr(s.Cond)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	post, err := renderForClause(s.Post)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := RenderStatements(s.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("for (%s; %s; %s) {\n%s}\n", init, cond, post, indentBlock(body)), nil
+}
+
+// renderForClause renders a for-loop's init/post clause, which - unlike a
+// full statement - has no trailing semicolon of its own; the for-header
+// supplies the separating semicolons.
+func renderForClause(node ast.Node) (string, error) {
+	if node == nil {
+		return "", nil
+	}
+	switch n := node.(type) {
+	case *ast.InferredDeclStmt:
+		value, err := renderExpr(n.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s = %s", inferCType(n.Value), n.Name, value), nil
+	case *ast.AssignStmt:
+		target, err := renderExpr(n.Target)
+		if err != nil {
+			return "", err
+		}
+		value, err := renderExpr(n.Value)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s = %s", target, value), nil
+	case ast.Expr:
+		return renderExpr(n)
+	default:
+		return "", fmt.Errorf("codegen: no C rendering for for-loop clause %T", node)
+	}
+}
+
+func renderWhileStmt(s *ast.WhileStmt) (string, error) {
+	cond, err := renderExpr(s.Cond)
+	if err != nil {
+		return "", err
+	}
+	body, err := RenderStatements(s.Body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("while (%s) {\n%s}\n", cond, indentBlock(body)), nil
+}
+
+func renderReturnStmt(s *ast.ReturnStmt) (string, error) {
+	if s.Value == nil {
+		return "return;\n", nil
+	}
+	value, err := renderExpr(s.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("return %s;\n", value), nil
+}
+
+func renderTypedDeclStmt(s *ast.TypedDeclStmt) (string, error) {
+	if s.Value == nil {
+		return fmt.Sprintf("%s %s;\n", mapCType(s.Type), s.Name), nil
+	}
+	value, err := renderExpr(s.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s = %s;\n", mapCType(s.Type), s.Name, value), nil
+}
+
+func renderInferredDeclStmt(s *ast.InferredDeclStmt) (string, error) {
+	value, err := renderExpr(s.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s = %s;\n", inferCType(s.Value), s.Name, value), nil
+}
+
+func renderExpr(e ast.Expr) (string, error) {
+	switch v := e.(type) {
+	case *ast.Ident:
+		return v.Name, nil
+	case *ast.IntLit:
+		return strconv.FormatInt(v.Value, 10), nil
+	case *ast.FloatLit:
+		return strconv.FormatFloat(v.Value, 'g', -1, 64), nil
+	case *ast.StringLit:
+		return strconv.Quote(v.Value), nil
+	case *ast.BoolLit:
+		if v.Value {
+			return "1", nil
+		}
+		return "0", nil
+	case *ast.UnaryExpr:
+		operand, err := renderExpr(v.Operand)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s%s", v.Op, operand), nil
+	case *ast.BinaryExpr:
+		left, err := renderExpr(v.Left)
+		if err != nil {
+			return "", err
+		}
+		right, err := renderExpr(v.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s %s %s)", left, v.Op, right), nil
+	case *ast.CallExpr:
+		callee, err := renderExpr(v.Callee)
+		if err != nil {
+			return "", err
+		}
+		args := make([]string, len(v.Args))
+		for i, a := range v.Args {
+			rendered, err := renderExpr(a)
+			if err != nil {
+				return "", err
+			}
+			args[i] = rendered
+		}
+		return fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", ")), nil
+	case *ast.IndexExpr:
+		target, err := renderExpr(v.Target)
+		if err != nil {
+			return "", err
+		}
+		index, err := renderExpr(v.Index)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s[%s]", target, index), nil
+	case *ast.SelectorExpr:
+		target, err := renderExpr(v.Target)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s.%s", target, v.Field), nil
+	default:
+		return "", fmt.Errorf("codegen: no C rendering for expression %T", e)
+	}
+}
+
+// mapCType maps a declared ZeLang type name to its C host type, matching
+// backend.Backend.MapType's scalar cases (kept independent here since this
+// renderer isn't routed through a Backend).
+func mapCType(t string) string {
+	switch t {
+	case "int":
+		return "int64_t"
+	case "float":
+		return "double"
+	case "string":
+		return "char*"
+	case "bool":
+		return "int"
+	default:
+		return t
+	}
+}
+
+// inferCType guesses a C host type for a `name := value` declaration from
+// the literal on the right-hand side.
+func inferCType(e ast.Expr) string {
+	switch e.(type) {
+	case *ast.FloatLit:
+		return "double"
+	case *ast.StringLit:
+		return "char*"
+	case *ast.BoolLit:
+		return "int"
+	default:
+		return "int64_t"
+	}
+}
+
+// indentBlock indents every non-empty line of s by one tab, for nesting
+// inside an if/for/while body.
+func indentBlock(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	var out bytes.Buffer
+	for _, line := range lines {
+		if line == "" {
+			out.WriteString("\n")
+			continue
+		}
+		out.WriteString("\t")
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+	return out.String()
+}
@@ -2,101 +2,51 @@ package codegen
 
 import (
 	"bytes"
-	"embed"
 	"fmt"
 	"strings"
-	"text/template"
 
 	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/codegen/backend"
+	"github.com/gunesh/zelang/pkg/decorator"
+
+	_ "github.com/gunesh/zelang/pkg/codegen/backends/csqlite"
 )
 
-//go:embed templates/*.tmpl
-var templateFS embed.FS
+// DefaultBackend is the backend used when the caller doesn't select one.
+const DefaultBackend = "c-sqlite-microhttpd"
 
-// TemplateGenerator generates C code using templates
+// TemplateGenerator walks a parsed program and renders it through a
+// pluggable Backend.
 type TemplateGenerator struct {
-	templates *template.Template
-	structs   []*ast.StructDecl
-	pages     []*ast.PageDecl
-	handlers  []*ast.HandlerDecl
-	hasWeb    bool
-}
-
-// Template data structures
-type FieldData struct {
-	Name            string
-	CType           string
-	SQLType         string
-	Constraints     string
-	Title           string
-	IsBool          bool
-	IsArray         bool
-	IsAutoIncrement bool
-}
-
-type ParamData struct {
-	Type string
-	Name string
-}
-
-type FormFieldData struct {
-	Name      string
-	Label     string
-	InputType string
-	Required  bool
-}
-
-type HTMLTemplateData struct {
-	PageNameLower string
-	PageTitle     string
-	HasDataList   bool
-	HasForm       bool
-	StructName    string
-	TableName     string
-	Fields        []FieldData
-	FormFields    []FormFieldData
+	backend  backend.Backend
+	structs  []*ast.StructDecl
+	pages    []*ast.PageDecl
+	handlers []*ast.HandlerDecl
+	hasWeb   bool
 }
 
-type CRUDTemplateData struct {
-	StructName   string
-	TableName    string
-	Params       []ParamData
-	BindFields   []FieldData
-	AllFields    []FieldData
-	Fields       []FieldData
-	FieldNames   string
-	Placeholders string
-}
-
-// NewTemplateGenerator creates a new template-based generator
+// NewTemplateGenerator creates a generator using DefaultBackend.
 func NewTemplateGenerator() (*TemplateGenerator, error) {
-	// Custom template functions
-	funcMap := template.FuncMap{
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"lt":     func(a, b int) bool { return a < b },
-		"len":    func(v interface{}) int { return len(v.([]FieldData)) },
-		"title":  strings.Title,
-		"printf": fmt.Sprintf,
-	}
+	return NewTemplateGeneratorWithBackend(DefaultBackend)
+}
 
-	// Parse all templates
-	tmpl, err := template.New("").Funcs(funcMap).ParseFS(templateFS, "templates/*.tmpl")
+// NewTemplateGeneratorWithBackend creates a generator targeting the named backend.
+func NewTemplateGeneratorWithBackend(name string) (*TemplateGenerator, error) {
+	b, err := backend.Get(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse templates: %w", err)
+		return nil, fmt.Errorf("failed to load backend %q: %w", name, err)
 	}
 
 	return &TemplateGenerator{
-		templates: tmpl,
-		structs:   []*ast.StructDecl{},
-		pages:     []*ast.PageDecl{},
-		handlers:  []*ast.HandlerDecl{},
-		hasWeb:    false,
+		backend:  b,
+		structs:  []*ast.StructDecl{},
+		pages:    []*ast.PageDecl{},
+		handlers: []*ast.HandlerDecl{},
+		hasWeb:   false,
 	}, nil
 }
 
-// Generate generates C code using templates
+// Generate generates code for program through the selected backend.
 func (g *TemplateGenerator) Generate(program *ast.Program) (string, error) {
 	var output bytes.Buffer
 
@@ -114,28 +64,35 @@ func (g *TemplateGenerator) Generate(program *ast.Program) (string, error) {
 		}
 	}
 
-	// Generate headers (still using direct code for now)
-	g.generateHeaders(&output)
+	output.WriteString(g.backend.Headers(g.hasWeb))
 
-	// Generate struct definitions using templates
+	// Generate struct definitions
 	for _, s := range g.structs {
-		if err := g.generateStructWithTemplate(&output, s); err != nil {
+		rendered, err := g.backend.RenderStruct(g.prepareStructData(s))
+		if err != nil {
 			return "", err
 		}
+		output.WriteString(rendered)
+		output.WriteString("\n")
 	}
 
-	// Generate CRUD functions using templates
+	// Generate CRUD functions
 	for _, s := range g.structs {
-		if err := g.generateCRUDWithTemplates(&output, s); err != nil {
+		tableName := g.getTableName(s)
+		rendered, err := g.backend.RenderCRUD(g.prepareCRUDData(s, tableName))
+		if err != nil {
 			return "", err
 		}
+		output.WriteString(rendered)
 	}
 
 	// Generate web server if needed
 	if g.hasWeb {
-		if err := g.generateWebServerWithTemplates(&output); err != nil {
+		rendered, err := g.backend.RenderWebServer(g.prepareWebServerData())
+		if err != nil {
 			return "", err
 		}
+		output.WriteString(rendered)
 	} else {
 		g.generateMainOld(&output)
 	}
@@ -143,227 +100,52 @@ func (g *TemplateGenerator) Generate(program *ast.Program) (string, error) {
 	return output.String(), nil
 }
 
-// generateHeaders generates C headers
-func (g *TemplateGenerator) generateHeaders(output *bytes.Buffer) {
-	output.WriteString(`#include <stdio.h>
-#include <stdlib.h>
-#include <string.h>
-#include <sqlite3.h>
-`)
-	if g.hasWeb {
-		output.WriteString(`#include <ctype.h>
-#include <microhttpd.h>
-`)
-	}
-	output.WriteString(`
-// Global database connection
-sqlite3 *db = NULL;
-
-`)
-	if g.hasWeb {
-		output.WriteString(`// Global HTTP server
-struct MHD_Daemon *http_daemon = NULL;
-
-`)
-	}
-}
-
-// generateCRUDWithTemplates generates CRUD functions using templates
-func (g *TemplateGenerator) generateCRUDWithTemplates(output *bytes.Buffer, s *ast.StructDecl) error {
-	tableName := g.getTableName(s)
-
-	// Prepare data for templates
-	data := g.prepareCRUDData(s, tableName)
-
-	// Generate CREATE function
-	if err := g.templates.ExecuteTemplate(output, "crud_create.tmpl", data); err != nil {
-		return fmt.Errorf("failed to execute crud_create template: %w", err)
-	}
-	output.WriteString("\n\n")
-
-	// Generate FIND function
-	if err := g.templates.ExecuteTemplate(output, "crud_find.tmpl", data); err != nil {
-		return fmt.Errorf("failed to execute crud_find template: %w", err)
-	}
-	output.WriteString("\n\n")
-
-	// Generate ALL function
-	if err := g.templates.ExecuteTemplate(output, "crud_all.tmpl", data); err != nil {
-		return fmt.Errorf("failed to execute crud_all template: %w", err)
-	}
-	output.WriteString("\n\n")
-
-	// Generate DELETE function
-	if err := g.templates.ExecuteTemplate(output, "crud_delete.tmpl", data); err != nil {
-		return fmt.Errorf("failed to execute crud_delete template: %w", err)
-	}
-	output.WriteString("\n\n")
-
-	// Generate INIT_TABLE function
-	if err := g.templates.ExecuteTemplate(output, "crud_init_table.tmpl", data); err != nil {
-		return fmt.Errorf("failed to execute crud_init_table template: %w", err)
-	}
-	output.WriteString("\n\n")
-
-	return nil
-}
-
-// generateStructWithTemplate generates struct definition using template
-func (g *TemplateGenerator) generateStructWithTemplate(output *bytes.Buffer, s *ast.StructDecl) error {
-	type StructTemplateData struct {
-		StructName string
-		Fields     []FieldData
-	}
-
-	data := StructTemplateData{
+// prepareStructData prepares data for RenderStruct.
+func (g *TemplateGenerator) prepareStructData(s *ast.StructDecl) backend.StructData {
+	data := backend.StructData{
 		StructName: s.Name,
-		Fields:     []FieldData{},
+		Fields:     []backend.FieldData{},
 	}
 
 	for _, field := range s.Fields {
-		data.Fields = append(data.Fields, FieldData{
-			Name:    field.Name,
-			CType:   mapType(field.Type),
-			IsArray: field.IsArray,
+		data.Fields = append(data.Fields, backend.FieldData{
+			Name:     field.Name,
+			HostType: g.backend.MapType(field.Type),
+			IsArray:  field.IsArray,
 		})
 	}
 
-	if err := g.templates.ExecuteTemplate(output, "struct_def.tmpl", data); err != nil {
-		return fmt.Errorf("failed to execute struct_def template: %w", err)
-	}
-	output.WriteString("\n")
-
-	return nil
-}
-
-// generateWebServerWithTemplates generates web server using templates
-func (g *TemplateGenerator) generateWebServerWithTemplates(output *bytes.Buffer) error {
-	// Generate HTML header constants using template
-	if err := g.templates.ExecuteTemplate(output, "html_header.tmpl", nil); err != nil {
-		return fmt.Errorf("failed to execute html_header template: %w", err)
-	}
-	output.WriteString("\n")
-
-	// Generate page rendering function
-	if len(g.pages) > 0 && len(g.structs) > 0 {
-		page := g.pages[0]
-		s := g.structs[0]
-
-		data := g.prepareHTMLData(page, s)
-
-		if err := g.templates.ExecuteTemplate(output, "html_page.tmpl", data); err != nil {
-			return fmt.Errorf("failed to execute html_page template: %w", err)
-		}
-		output.WriteString("\n\n")
-	}
-
-	// Generate HTTP route handler using template
-	if len(g.pages) > 0 && len(g.structs) > 0 {
-		page := g.pages[0]
-		s := g.structs[0]
-		data := g.prepareHTMLData(page, s)
-
-		// Convert FormFields to FieldData for the handler template
-		handlerData := struct {
-			StructName    string
-			TableName     string
-			PageNameLower string
-			FormFields    []FieldData
-		}{
-			StructName:    data.StructName,
-			TableName:     data.TableName,
-			PageNameLower: data.PageNameLower,
-			FormFields:    []FieldData{},
-		}
-
-		// Get non-auto fields for form processing
-		for _, field := range s.Fields {
-			if field.IsArray {
-				continue
-			}
-			isAuto := false
-			for _, dec := range field.Decorators {
-				if dec.Name == "autoincrement" || dec.Name == "primary" {
-					isAuto = true
-				}
-			}
-			if !isAuto {
-				handlerData.FormFields = append(handlerData.FormFields, FieldData{
-					Name:   field.Name,
-					CType:  mapType(field.Type),
-					IsBool: field.Type == "bool",
-				})
-			}
-		}
-
-		if err := g.templates.ExecuteTemplate(output, "http_handler.tmpl", handlerData); err != nil {
-			return fmt.Errorf("failed to execute http_handler template: %w", err)
-		}
-		output.WriteString("\n\n")
-	}
-
-	// Generate web main using template
-	mainData := struct {
-		Structs []struct {
-			Name string
-		}
-	}{
-		Structs: []struct {
-			Name string
-		}{},
-	}
-
-	for _, s := range g.structs {
-		mainData.Structs = append(mainData.Structs, struct {
-			Name string
-		}{Name: s.Name})
-	}
-
-	if err := g.templates.ExecuteTemplate(output, "web_main.tmpl", mainData); err != nil {
-		return fmt.Errorf("failed to execute web_main template: %w", err)
-	}
-
-	return nil
+	return data
 }
 
-// prepareCRUDData prepares data for CRUD templates
-func (g *TemplateGenerator) prepareCRUDData(s *ast.StructDecl, tableName string) CRUDTemplateData {
-	data := CRUDTemplateData{
+// prepareCRUDData prepares data for RenderCRUD.
+func (g *TemplateGenerator) prepareCRUDData(s *ast.StructDecl, tableName string) backend.CRUDTemplateData {
+	data := backend.CRUDTemplateData{
 		StructName: s.Name,
 		TableName:  tableName,
-		Params:     []ParamData{},
-		BindFields: []FieldData{},
-		AllFields:  []FieldData{},
-		Fields:     []FieldData{},
+		Params:     []backend.ParamData{},
+		BindFields: []backend.FieldData{},
+		AllFields:  []backend.FieldData{},
+		Fields:     []backend.FieldData{},
 	}
 
 	fieldNames := []string{}
 	placeholders := []string{}
 
-	// Process fields
 	for _, field := range s.Fields {
 		if field.IsArray {
 			continue
 		}
 
-		isAuto := false
-		isPrimary := false
-		for _, dec := range field.Decorators {
-			if dec.Name == "autoincrement" || dec.Name == "timestamp" {
-				isAuto = true
-			}
-			if dec.Name == "primary" {
-				isPrimary = true
-			}
-		}
+		isAuto := decorator.IsAutoIncrement(field.Decorators)
 
-		cType := mapType(field.Type)
-		fieldData := FieldData{
+		hostType := g.backend.MapType(field.Type)
+		fieldData := backend.FieldData{
 			Name:            field.Name,
-			CType:           cType,
-			SQLType:         mapSQLType(field.Type),
-			Constraints:     getFieldConstraints(field),
-			IsAutoIncrement: isAuto && isPrimary,
+			HostType:        hostType,
+			SQLType:         g.backend.MapSQLType(field.Type),
+			Constraints:     g.backend.FieldConstraints(field),
+			IsAutoIncrement: isAuto && decorator.IsPrimaryKey(field.Decorators),
 			IsBool:          field.Type == "bool",
 		}
 
@@ -371,8 +153,8 @@ func (g *TemplateGenerator) prepareCRUDData(s *ast.StructDecl, tableName string)
 		data.Fields = append(data.Fields, fieldData)
 
 		if !isAuto {
-			data.Params = append(data.Params, ParamData{
-				Type: cType,
+			data.Params = append(data.Params, backend.ParamData{
+				Type: hostType,
 				Name: field.Name,
 			})
 			data.BindFields = append(data.BindFields, fieldData)
@@ -387,67 +169,57 @@ func (g *TemplateGenerator) prepareCRUDData(s *ast.StructDecl, tableName string)
 	return data
 }
 
-// prepareHTMLData prepares data for HTML templates
-func (g *TemplateGenerator) prepareHTMLData(page *ast.PageDecl, s *ast.StructDecl) HTMLTemplateData {
+// prepareHTMLData prepares the page+struct pair for RenderWebServer.
+func (g *TemplateGenerator) prepareHTMLData(page *ast.PageDecl, s *ast.StructDecl) backend.HTMLTemplateData {
 	tableName := g.getTableName(s)
 
-	data := HTMLTemplateData{
+	data := backend.HTMLTemplateData{
 		PageNameLower: strings.ToLower(page.Name),
 		PageTitle:     page.Name,
 		HasDataList:   true,
 		HasForm:       true,
 		StructName:    s.Name,
 		TableName:     tableName,
-		Fields:        []FieldData{},
-		FormFields:    []FormFieldData{},
+		Fields:        []backend.FieldData{},
+		FormFields:    []backend.FormFieldData{},
+		Formats:       g.pageFormats(page),
 	}
 
-	// Prepare field data
 	for _, field := range s.Fields {
 		if field.IsArray {
 			continue
 		}
 
-		cType := mapType(field.Type)
-		data.Fields = append(data.Fields, FieldData{
+		hostType := g.backend.MapType(field.Type)
+		data.Fields = append(data.Fields, backend.FieldData{
 			Name:        field.Name,
-			CType:       cType,
-			SQLType:     mapSQLType(field.Type),
-			Constraints: getFieldConstraints(field),
+			HostType:    hostType,
+			SQLType:     g.backend.MapSQLType(field.Type),
+			Constraints: g.backend.FieldConstraints(field),
 			Title:       strings.Title(field.Name),
 			IsBool:      field.Type == "bool",
 		})
 
-		// Skip auto fields in forms
-		isAuto := false
-		for _, dec := range field.Decorators {
-			if dec.Name == "autoincrement" || dec.Name == "primary" {
-				isAuto = true
-			}
-		}
+		isAuto := decorator.IsAutoIncrement(field.Decorators) || decorator.IsPrimaryKey(field.Decorators)
 
 		if !isAuto {
-			inputType := "text"
-			if field.Name == "description" {
-				inputType = "textarea"
-			} else if field.Type == "bool" {
-				inputType = "checkbox"
-			} else if field.Type == "int" {
-				inputType = "number"
-			}
-
-			required := false
-			for _, dec := range field.Decorators {
-				if dec.Name == "required" {
-					required = true
+			inputType := decorator.FormInputOverride(field.Decorators)
+			if inputType == "" {
+				inputType = "text"
+				if field.Name == "description" {
+					inputType = "textarea"
+				} else if field.Type == "bool" {
+					inputType = "checkbox"
+				} else if field.Type == "int" {
+					inputType = "number"
 				}
 			}
 
-			data.FormFields = append(data.FormFields, FormFieldData{
+			data.FormFields = append(data.FormFields, backend.FormFieldData{
 				Name:      field.Name,
 				Label:     strings.Title(field.Name),
 				InputType: inputType,
-				Required:  required,
+				Required:  decorator.FormRequired(field.Decorators),
 			})
 		}
 	}
@@ -455,67 +227,149 @@ func (g *TemplateGenerator) prepareHTMLData(page *ast.PageDecl, s *ast.StructDec
 	return data
 }
 
-// Helper function
-func (g *TemplateGenerator) getTableName(s *ast.StructDecl) string {
-	for _, dec := range s.Decorators {
-		if dec.Name == "table" && len(dec.Args) > 0 {
-			return strings.Trim(dec.Args[0], `"`)
+// pageFormats reads a page's @formats decorator (e.g. @formats(html, json))
+// and returns its normalized, deduplicated output formats. An ambiguous or
+// missing declaration falls back to [html]; html is always included since
+// the page's own route keeps serving it regardless of what else a page
+// declares.
+func (g *TemplateGenerator) pageFormats(page *ast.PageDecl) []backend.OutputFormat {
+	var declared []string
+	for _, dec := range page.Decorators {
+		if dec.Name == "formats" || dec.Name == "format" {
+			declared = append(declared, dec.Args...)
 		}
 	}
-	return strings.ToLower(s.Name) + "s"
-}
+	if len(declared) == 0 {
+		return []backend.OutputFormat{backend.FormatHTML}
+	}
 
-func mapType(zlType string) string {
-	switch zlType {
-	case "int":
-		return "int64_t"
-	case "float":
-		return "double"
-	case "string":
-		return "char*"
-	case "bool":
-		return "int"
-	case "date", "datetime":
-		return "char*"
-	default:
-		return zlType
+	seen := map[backend.OutputFormat]bool{}
+	formats := []backend.OutputFormat{}
+	hasHTML := false
+	for _, name := range declared {
+		f := backend.NormalizeFormat(strings.Trim(name, `"`))
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		formats = append(formats, f)
+		if f == backend.FormatHTML {
+			hasHTML = true
+		}
 	}
+	if !hasHTML {
+		formats = append([]backend.OutputFormat{backend.FormatHTML}, formats...)
+	}
+	return formats
 }
 
-func mapSQLType(zlType string) string {
-	switch zlType {
-	case "int":
-		return "INTEGER"
-	case "float":
-		return "REAL"
-	case "string":
-		return "TEXT"
-	case "bool":
-		return "INTEGER"
-	case "date", "datetime":
-		return "TEXT"
-	default:
-		return "TEXT"
+// prepareWebServerData prepares data for RenderWebServer.
+func (g *TemplateGenerator) prepareWebServerData() backend.WebServerData {
+	data := backend.WebServerData{
+		Structs: []backend.StructSummary{},
+	}
+
+	for _, s := range g.structs {
+		data.Structs = append(data.Structs, backend.StructSummary{Name: s.Name})
 	}
+
+	for i, page := range g.pages {
+		s := g.resolveModel(page, i)
+		if s == nil {
+			continue
+		}
+
+		html := g.prepareHTMLData(page, s)
+		html.Route = g.pageRoute(page, i)
+		data.Pages = append(data.Pages, html)
+
+		handler := backend.HandlerData{
+			StructName:    html.StructName,
+			TableName:     html.TableName,
+			PageNameLower: html.PageNameLower,
+			Route:         html.Route,
+			FormFields:    []backend.FieldData{},
+			ExtraFormats:  []backend.FormatRoute{},
+		}
+
+		for _, format := range html.Formats {
+			if format == backend.FormatHTML {
+				continue
+			}
+			handler.ExtraFormats = append(handler.ExtraFormats, backend.FormatRoute{
+				Format:      format,
+				Route:       fmt.Sprintf("/%s.%s", html.PageNameLower, format),
+				ContentType: backend.ContentType(format),
+				RenderFunc:  fmt.Sprintf("render_%s_%s_page", html.PageNameLower, format),
+			})
+		}
+
+		for _, field := range s.Fields {
+			if field.IsArray {
+				continue
+			}
+			isAuto := decorator.IsAutoIncrement(field.Decorators) || decorator.IsPrimaryKey(field.Decorators)
+			if !isAuto {
+				handler.FormFields = append(handler.FormFields, backend.FieldData{
+					Name:     field.Name,
+					HostType: g.backend.MapType(field.Type),
+					IsBool:   field.Type == "bool",
+				})
+			}
+		}
+
+		data.Handlers = append(data.Handlers, handler)
+	}
+
+	return data
 }
 
-func getFieldConstraints(field *ast.FieldDecl) string {
-	constraints := ""
-
-	for _, dec := range field.Decorators {
-		switch dec.Name {
-		case "primary":
-			constraints += " PRIMARY KEY"
-		case "autoincrement":
-			constraints += " AUTOINCREMENT"
-		case "required":
-			constraints += " NOT NULL"
-		case "unique":
-			constraints += " UNIQUE"
+// resolveModel finds the struct a page binds to via @model(Name), falling
+// back to a positional pairing (page i <-> g.structs[i]) for pages that
+// don't declare one, and to the sole struct for single-model programs.
+// Returns nil if the program declares no structs at all.
+func (g *TemplateGenerator) resolveModel(page *ast.PageDecl, index int) *ast.StructDecl {
+	for _, dec := range page.Decorators {
+		if dec.Name != "model" || len(dec.Args) == 0 {
+			continue
+		}
+		modelName := strings.Trim(dec.Args[0], `"`)
+		for _, s := range g.structs {
+			if s.Name == modelName {
+				return s
+			}
 		}
 	}
 
-	return constraints
+	if len(g.structs) == 0 {
+		return nil
+	}
+	if index < len(g.structs) {
+		return g.structs[index]
+	}
+	return g.structs[0]
+}
+
+// pageRoute returns the URL path a page's default (HTML) view is served
+// from. ast.PageDecl.Route wins when the page declared one explicitly;
+// otherwise the first page keeps "/" so single-page programs behave exactly
+// as before, and later pages route off their own name.
+func (g *TemplateGenerator) pageRoute(page *ast.PageDecl, index int) string {
+	if page.Route != "" {
+		return page.Route
+	}
+	if index == 0 {
+		return "/"
+	}
+	return "/" + strings.ToLower(page.Name)
+}
+
+// Helper function
+func (g *TemplateGenerator) getTableName(s *ast.StructDecl) string {
+	if name, ok := decorator.TableName(s.Decorators); ok {
+		return name
+	}
+	return strings.ToLower(s.Name) + "s"
 }
 
 // Old generation methods (temporary - to be replaced with templates)
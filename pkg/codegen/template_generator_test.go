@@ -90,3 +90,43 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestRenderStatementsIfReturn(t *testing.T) {
+	// handler Greet(string name) {
+	//     if (name == "") {
+	//         return 0;
+	//     }
+	//     return 1;
+	// }
+	body := []ast.Node{
+		&ast.IfStmt{
+			Cond: &ast.BinaryExpr{
+				Left:  &ast.Ident{Name: "name"},
+				Op:    "==",
+				Right: &ast.StringLit{Value: ""},
+			},
+			Body: []ast.Node{
+				&ast.ReturnStmt{Value: &ast.IntLit{Value: 0}},
+			},
+		},
+		&ast.ReturnStmt{Value: &ast.IntLit{Value: 1}},
+	}
+
+	code, err := RenderStatements(body)
+	if err != nil {
+		t.Fatalf("Failed to render statements: %v", err)
+	}
+
+	expectedPatterns := []string{
+		`if (`,
+		`name == ""`,
+		"return 0;",
+		"return 1;",
+	}
+
+	for _, pattern := range expectedPatterns {
+		if !strings.Contains(code, pattern) {
+			t.Errorf("Rendered code missing expected pattern: %s\ngot:\n%s", pattern, code)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// A `file` (alias `blob`) field type switches generateFormHTML to a
+// multipart/form-data form and handle_request's create-POST handler to a
+// hand-rolled multipart parser alongside the existing urlencoded one - same
+// "no external dependency" trade-off fs_storage.go and json_api.go make.
+// The uploaded payload is written under uploads/ with a random-hex name, and
+// the field stores {filename, mime_type, path, size} the same way every
+// other string field stores its value: as the column's char* text (see
+// jsonFieldWrite/generateFromJSON's default case, which already treats an
+// unrecognized field type as a plain string - this just happens to be one
+// whose string is a small JSON object).
+
+// isFileField reports whether field is a `file`/`blob` upload field.
+func isFileField(field *ast.FieldDecl) bool {
+	return field.Type == "file" || field.Type == "blob"
+}
+
+// structHasFileField reports whether s has any upload field.
+func structHasFileField(s *ast.StructDecl) bool {
+	for _, field := range s.Fields {
+		if isFileField(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesFileUploads reports whether any struct in the program has an upload
+// field.
+func (g *CGenerator) usesFileUploads() bool {
+	for _, s := range g.structs {
+		if structHasFileField(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateUploadRuntime emits the multipart parser and its supporting mime
+// sniffing/random-name/mkdir helpers. No-op unless some struct has an
+// upload field.
+func (g *CGenerator) generateUploadRuntime() {
+	if !g.usesFileUploads() {
+		return
+	}
+
+	g.output.WriteString("// Multipart/form-data uploads for `file`/`blob` fields.\n")
+	g.output.WriteString(`static void zl_upload_mkdir_p(const char *path) {
+    mkdir(path, 0755);
+}
+
+static void zl_upload_random_hex(char *out, int nbytes) {
+    for (int i = 0; i < nbytes; i++) sprintf(out + i * 2, "%02x", rand() % 256);
+    out[nbytes * 2] = '\0';
+}
+
+// zl_upload_memfind is strstr/memmem for a buffer that may contain embedded
+// NULs, which raw upload data can - strstr would stop at the first one.
+static const char* zl_upload_memfind(const char *haystack, size_t haystack_len, const char *needle, size_t needle_len) {
+    if (needle_len == 0 || haystack_len < needle_len) return NULL;
+    for (size_t i = 0; i + needle_len <= haystack_len; i++) {
+        if (memcmp(haystack + i, needle, needle_len) == 0) return haystack + i;
+    }
+    return NULL;
+}
+
+// zl_upload_sniff_mime guesses a mime type from the leading bytes of an
+// upload, independent of whatever the client claimed.
+static const char* zl_upload_sniff_mime(const unsigned char *data, size_t len) {
+    if (len >= 4 && memcmp(data, "%PDF", 4) == 0) return "application/pdf";
+    if (len >= 4 && data[0] == 0x89 && memcmp(data + 1, "PNG", 3) == 0) return "image/png";
+    if (len >= 2 && data[0] == 0xff && data[1] == 0xd8) return "image/jpeg";
+    if (len >= 4 && memcmp(data, "PK\x03\x04", 4) == 0) return "application/zip";
+    if (len >= 1 && data[0] == '<') return "text/html";
+    return "application/octet-stream";
+}
+
+// zl_upload_write_file writes data under uploads/ with a random-hex name
+// (keeping the original extension, if any) and writes the stored path into
+// path_out.
+static void zl_upload_write_file(const char *filename, const unsigned char *data, size_t len, char *path_out) {
+    const char *ext = strrchr(filename, '.');
+    char name[64];
+    zl_upload_random_hex(name, 16);
+    if (ext) {
+        char safe_ext[16];
+        snprintf(safe_ext, sizeof(safe_ext), "%s", ext);
+        strncat(name, safe_ext, sizeof(name) - strlen(name) - 1);
+    }
+    snprintf(path_out, 512, "uploads/%s", name);
+    FILE *f = fopen(path_out, "wb");
+    if (f) {
+        fwrite(data, 1, len, f);
+        fclose(f);
+    }
+}
+
+// parse_multipart walks a multipart/form-data body by boundary, the way
+// parse_form_data walks an urlencoded one: plain fields land in
+// fields[]/values[] as-is, and file parts land there too, with values[]
+// holding a {filename, mime_type, path, size} JSON object instead of the
+// raw bytes (which have already been written to uploads/ by this point).
+void parse_multipart(const char *boundary, const char *data, size_t len, char fields[][256], char values[][256], int *count) {
+    *count = 0;
+
+    char delim[300];
+    int delim_len = snprintf(delim, sizeof(delim), "--%s", boundary);
+
+    const char *pos = data;
+    size_t remaining = len;
+
+    while (*count < 10) {
+        const char *part_start = zl_upload_memfind(pos, remaining, delim, delim_len);
+        if (!part_start) break;
+        part_start += delim_len;
+        remaining -= (part_start - pos);
+        pos = part_start;
+
+        if (remaining >= 2 && pos[0] == '-' && pos[1] == '-') break; // closing boundary
+
+        const char *next_boundary = zl_upload_memfind(pos, remaining, delim, delim_len);
+        size_t part_len = next_boundary ? (size_t)(next_boundary - pos) : remaining;
+
+        const char *headers_end = zl_upload_memfind(pos, part_len, "\r\n\r\n", 4);
+        if (!headers_end) { pos += part_len; remaining -= part_len; continue; }
+
+        char headers[1024];
+        size_t headers_len = headers_end - pos;
+        if (headers_len >= sizeof(headers)) headers_len = sizeof(headers) - 1;
+        memcpy(headers, pos, headers_len);
+        headers[headers_len] = '\0';
+
+        const char *body = headers_end + 4;
+        size_t body_len = part_len - headers_len - 4;
+        if (body_len >= 2 && body[body_len - 2] == '\r' && body[body_len - 1] == '\n') body_len -= 2;
+
+        char field_name[256] = "";
+        const char *name_p = strstr(headers, "name=\"");
+        if (name_p) {
+            name_p += 6;
+            const char *name_end = strchr(name_p, '"');
+            if (name_end) {
+                size_t n = name_end - name_p;
+                if (n >= sizeof(field_name)) n = sizeof(field_name) - 1;
+                memcpy(field_name, name_p, n);
+                field_name[n] = '\0';
+            }
+        }
+
+        const char *filename_p = strstr(headers, "filename=\"");
+        if (filename_p && field_name[0] != '\0') {
+            filename_p += 10;
+            const char *filename_end = strchr(filename_p, '"');
+            char filename[256] = "";
+            if (filename_end) {
+                size_t n = filename_end - filename_p;
+                if (n >= sizeof(filename)) n = sizeof(filename) - 1;
+                memcpy(filename, filename_p, n);
+                filename[n] = '\0';
+            }
+            if (filename[0] != '\0') {
+                const char *mime_type = zl_upload_sniff_mime((const unsigned char*)body, body_len);
+                char path[512];
+                zl_upload_write_file(filename, (const unsigned char*)body, body_len, path);
+                strcpy(fields[*count], field_name);
+                snprintf(values[*count], 256, "{\"filename\":\"%s\",\"mime_type\":\"%s\",\"path\":\"%s\",\"size\":%zu}",
+                    filename, mime_type, path, body_len);
+                (*count)++;
+            }
+        } else if (field_name[0] != '\0') {
+            strcpy(fields[*count], field_name);
+            size_t n = body_len;
+            if (n >= 256) n = 255;
+            memcpy(values[*count], body, n);
+            values[*count][n] = '\0';
+            (*count)++;
+        }
+
+        pos += part_len;
+        remaining -= part_len;
+    }
+}
+
+`)
+}
+
+// fileFormInput renders the <input type='file'> generateFormHTML emits for
+// an upload field in place of the usual text/number/checkbox input.
+func fileFormInput(field *ast.FieldDecl) string {
+	return fmt.Sprintf("    offset += sprintf(html + offset, \"<input type='file' name='%s' class='form-control'>\\n\");\n", field.Name)
+}
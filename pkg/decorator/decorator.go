@@ -0,0 +1,317 @@
+// Package decorator gives `@name(...)` AST decorators typed, registered
+// behavior instead of the string comparisons on Decorator.Name that used to
+// be scattered across codegen (one switch for SQL constraints, another for
+// auto-increment detection, another for form input types). Each decorator
+// registers a Handler; codegen asks the registry instead of knowing decorator
+// names itself, so a new decorator (e.g. @index, @default, @foreignkey) only
+// needs a Handler registered here, not edits to every codegen call site.
+package decorator
+
+import (
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+// Target is the kind of AST node a decorator is valid on.
+type Target int
+
+const (
+	TargetField Target = iota
+	TargetStruct
+)
+
+// Handler is the typed behavior behind one decorator name. Implementations
+// embed BaseHandler and override only the methods relevant to them.
+type Handler interface {
+	// Name is the decorator identifier, e.g. "primary" for @primary.
+	Name() string
+	// AppliesTo reports which kind of declaration this decorator is valid on.
+	AppliesTo() Target
+	// SQLConstraint returns the column constraint clause (leading space
+	// included, e.g. " NOT NULL") this decorator contributes for dialect,
+	// or "" if it contributes none.
+	SQLConstraint(dec *ast.Decorator, dialect string) string
+	// AffectsAutoIncrement reports whether this decorator marks a field as
+	// auto-populated (never supplied by the caller), e.g. @autoincrement,
+	// @timestamp.
+	AffectsAutoIncrement(dec *ast.Decorator) bool
+	// AffectsPrimaryKey reports whether this decorator marks a field as
+	// (part of) the table's primary key.
+	AffectsPrimaryKey(dec *ast.Decorator) bool
+	// FormInputOverride returns the HTML input type this decorator forces
+	// for generated forms (e.g. "textarea"), or "" to leave the default.
+	FormInputOverride(dec *ast.Decorator) string
+	// FormRequired reports whether this decorator marks a generated form
+	// field as required.
+	FormRequired(dec *ast.Decorator) bool
+	// TableName returns an explicit table-name override carried by this
+	// decorator (e.g. @table("todos")), and whether it had one.
+	TableName(dec *ast.Decorator) (string, bool)
+	// RenamedFrom returns the column's previous name carried by this
+	// decorator (e.g. @renamedFrom("old_name")), and whether it had one.
+	RenamedFrom(dec *ast.Decorator) (string, bool)
+	// IsSearchable reports whether this decorator marks a field for
+	// inclusion in its struct's full-text search index.
+	IsSearchable(dec *ast.Decorator) bool
+	// Storage returns the alternative storage backend carried by this
+	// decorator (e.g. @storage("fs", "./data")) as (backend, path), and
+	// whether it had one.
+	Storage(dec *ast.Decorator) (backend string, path string, ok bool)
+}
+
+// BaseHandler supplies no-op defaults for Handler, so a concrete decorator
+// only needs to implement Name, AppliesTo, and whichever methods apply to it.
+type BaseHandler struct{}
+
+func (BaseHandler) SQLConstraint(*ast.Decorator, string) string { return "" }
+func (BaseHandler) AffectsAutoIncrement(*ast.Decorator) bool    { return false }
+func (BaseHandler) AffectsPrimaryKey(*ast.Decorator) bool       { return false }
+func (BaseHandler) FormInputOverride(*ast.Decorator) string     { return "" }
+func (BaseHandler) FormRequired(*ast.Decorator) bool            { return false }
+func (BaseHandler) TableName(*ast.Decorator) (string, bool)     { return "", false }
+func (BaseHandler) RenamedFrom(*ast.Decorator) (string, bool)   { return "", false }
+func (BaseHandler) IsSearchable(*ast.Decorator) bool            { return false }
+func (BaseHandler) Storage(*ast.Decorator) (string, string, bool) { return "", "", false }
+
+
+var registry = map[string]Handler{}
+
+// Register makes a decorator available under its Name. It is meant to be
+// called from this package's init function; user/plugin decorators can call
+// it the same way from their own init.
+func Register(h Handler) {
+	registry[h.Name()] = h
+}
+
+// Get returns the handler registered for name, if any.
+func Get(name string) (Handler, bool) {
+	h, ok := registry[name]
+	return h, ok
+}
+
+func init() {
+	Register(primaryHandler{})
+	Register(autoincrementHandler{})
+	Register(timestampHandler{})
+	Register(requiredHandler{})
+	Register(uniqueHandler{})
+	Register(tableHandler{})
+	Register(renamedFromHandler{})
+	Register(searchableHandler{})
+	Register(storageHandler{})
+}
+
+type primaryHandler struct{ BaseHandler }
+
+func (primaryHandler) Name() string      { return "primary" }
+func (primaryHandler) AppliesTo() Target { return TargetField }
+func (primaryHandler) SQLConstraint(*ast.Decorator, string) string {
+	return " PRIMARY KEY"
+}
+func (primaryHandler) AffectsPrimaryKey(*ast.Decorator) bool { return true }
+
+type autoincrementHandler struct{ BaseHandler }
+
+func (autoincrementHandler) Name() string      { return "autoincrement" }
+func (autoincrementHandler) AppliesTo() Target { return TargetField }
+func (autoincrementHandler) SQLConstraint(_ *ast.Decorator, dialect string) string {
+	switch dialect {
+	case "sqlite":
+		return " AUTOINCREMENT"
+	case "mysql":
+		return " AUTO_INCREMENT"
+	default:
+		// postgres picks autoincrement via MapSQLType's SERIAL/BIGSERIAL
+		// column type instead of a constraint clause.
+		return ""
+	}
+}
+func (autoincrementHandler) AffectsAutoIncrement(*ast.Decorator) bool { return true }
+
+// timestampHandler marks created-at/updated-at style fields as
+// auto-populated, matching the prior ad-hoc rule that @timestamp fields
+// (like @autoincrement ones) are never supplied by the caller.
+type timestampHandler struct{ BaseHandler }
+
+func (timestampHandler) Name() string                           { return "timestamp" }
+func (timestampHandler) AppliesTo() Target                      { return TargetField }
+func (timestampHandler) AffectsAutoIncrement(*ast.Decorator) bool { return true }
+
+type requiredHandler struct{ BaseHandler }
+
+func (requiredHandler) Name() string      { return "required" }
+func (requiredHandler) AppliesTo() Target { return TargetField }
+func (requiredHandler) SQLConstraint(*ast.Decorator, string) string {
+	return " NOT NULL"
+}
+func (requiredHandler) FormRequired(*ast.Decorator) bool { return true }
+
+type uniqueHandler struct{ BaseHandler }
+
+func (uniqueHandler) Name() string      { return "unique" }
+func (uniqueHandler) AppliesTo() Target { return TargetField }
+func (uniqueHandler) SQLConstraint(*ast.Decorator, string) string {
+	return " UNIQUE"
+}
+
+type tableHandler struct{ BaseHandler }
+
+func (tableHandler) Name() string      { return "table" }
+func (tableHandler) AppliesTo() Target { return TargetStruct }
+func (tableHandler) TableName(dec *ast.Decorator) (string, bool) {
+	if len(dec.Args) == 0 {
+		return "", false
+	}
+	return strings.Trim(dec.Args[0], `"`), true
+}
+
+// renamedFromHandler marks a field as the new name of a previously migrated
+// column, so the migration pipeline diffs it as a rename instead of a
+// drop+add (which would lose the column's data).
+type renamedFromHandler struct{ BaseHandler }
+
+func (renamedFromHandler) Name() string      { return "renamedFrom" }
+func (renamedFromHandler) AppliesTo() Target { return TargetField }
+func (renamedFromHandler) RenamedFrom(dec *ast.Decorator) (string, bool) {
+	if len(dec.Args) == 0 {
+		return "", false
+	}
+	return strings.Trim(dec.Args[0], `"`), true
+}
+
+// searchableHandler marks a string field for inclusion in its struct's FTS5
+// full-text index (generateInitTable creates the index once any field on a
+// struct carries this decorator).
+type searchableHandler struct{ BaseHandler }
+
+func (searchableHandler) Name() string                     { return "searchable" }
+func (searchableHandler) AppliesTo() Target                { return TargetField }
+func (searchableHandler) IsSearchable(*ast.Decorator) bool { return true }
+
+// storageHandler switches a struct from the SQL backend to a file-per-record
+// store, e.g. @storage("fs", "./data"). Like @table, it's a struct-level
+// decorator the generator reads up front rather than a behavior that flows
+// through constraint/form rendering.
+type storageHandler struct{ BaseHandler }
+
+func (storageHandler) Name() string      { return "storage" }
+func (storageHandler) AppliesTo() Target { return TargetStruct }
+func (storageHandler) Storage(dec *ast.Decorator) (string, string, bool) {
+	if len(dec.Args) == 0 {
+		return "", "", false
+	}
+	backend := strings.Trim(dec.Args[0], `"`)
+	path := ""
+	if len(dec.Args) > 1 {
+		path = strings.Trim(dec.Args[1], `"`)
+	}
+	return backend, path, true
+}
+
+// Constraints renders the SQL constraint clause contributed by decs, in
+// declaration order, for dialect.
+func Constraints(decs []*ast.Decorator, dialect string) string {
+	var sb strings.Builder
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok {
+			sb.WriteString(h.SQLConstraint(dec, dialect))
+		}
+	}
+	return sb.String()
+}
+
+// IsAutoIncrement reports whether decs contains a decorator that marks its
+// field as auto-populated (e.g. @autoincrement, @timestamp).
+func IsAutoIncrement(decs []*ast.Decorator) bool {
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok && h.AffectsAutoIncrement(dec) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrimaryKey reports whether decs marks its field as (part of) the
+// table's primary key.
+func IsPrimaryKey(decs []*ast.Decorator) bool {
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok && h.AffectsPrimaryKey(dec) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormInputOverride returns the first forced HTML input type among decs, or
+// "" if none of them override it.
+func FormInputOverride(decs []*ast.Decorator) string {
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok {
+			if t := h.FormInputOverride(dec); t != "" {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// FormRequired reports whether decs marks a generated form field as required.
+func FormRequired(decs []*ast.Decorator) bool {
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok && h.FormRequired(dec) {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName returns the explicit table name carried by decs (e.g. from
+// @table("todos")), and whether one was present.
+func TableName(decs []*ast.Decorator) (string, bool) {
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok {
+			if name, ok := h.TableName(dec); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// RenamedFrom returns the previous column name carried by decs (e.g. from
+// @renamedFrom("old_name")), and whether one was present.
+func RenamedFrom(decs []*ast.Decorator) (string, bool) {
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok {
+			if name, ok := h.RenamedFrom(dec); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IsSearchable reports whether decs marks its field for inclusion in its
+// struct's full-text search index (e.g. @searchable).
+func IsSearchable(decs []*ast.Decorator) bool {
+	for _, dec := range decs {
+		if h, ok := Get(dec.Name); ok && h.IsSearchable(dec) {
+			return true
+		}
+	}
+	return false
+}
+
+// Storage returns the alternative storage backend carried by decs (e.g. from
+// @storage("fs", "./data")), and whether one was present.
+func Storage(decs []*ast.Decorator) (backend string, path string, ok bool) {
+	for _, dec := range decs {
+		if h, found := Get(dec.Name); found {
+			if b, p, has := h.Storage(dec); has {
+				return b, p, true
+			}
+		}
+	}
+	return "", "", false
+}
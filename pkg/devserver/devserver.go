@@ -0,0 +1,427 @@
+// Package devserver implements `zelang serve`: it watches a directory of
+// .zl sources, re-runs the parse+codegen+compile pipeline whenever one
+// changes, and proxies to the rebuilt binary's own HTTP server. A build
+// failure is rendered in-page via build_error.tmpl, with a livereload
+// script injected into successful HTML responses so the browser refreshes
+// once the next build succeeds.
+package devserver
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gunesh/zelang/pkg/codegen"
+	"github.com/gunesh/zelang/pkg/lexer"
+	"github.com/gunesh/zelang/pkg/parser"
+)
+
+//go:embed templates/build_error.tmpl
+var templatesFS embed.FS
+
+// livereloadScript is injected before </body> in proxied HTML responses.
+// It polls /__livereload/wait, which blocks until the next successful
+// rebuild, and reloads the page when it returns.
+const livereloadScript = `<script>
+(function poll() {
+  fetch('/__livereload/wait').then(function() { location.reload(); }).catch(function() {
+    setTimeout(poll, 1000);
+  });
+})();
+</script>`
+
+// BuildError describes a single parse or compile failure, with enough
+// source position to render a useful in-page diagnostic.
+type BuildError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+	Excerpt string
+}
+
+// Config configures a Server.
+type Config struct {
+	// SourceDir is scanned (non-recursively is fine for small projects,
+	// but we walk it) for *.zl files on every poll tick.
+	SourceDir string
+	// Addr is where the supervising proxy listens, e.g. ":4000".
+	Addr string
+	// BackendAddr is where the compiled binary's own HTTP server listens,
+	// e.g. "127.0.0.1:8080". The generated C web server already binds
+	// this port (see backends/csqlite/templates/web_main.tmpl).
+	BackendAddr string
+	// PollInterval controls how often SourceDir is rescanned for changes.
+	// Defaults to 500ms.
+	PollInterval time.Duration
+	// BuildDir is where the generated .c file and compiled binary are
+	// written. Defaults to a temp directory.
+	BuildDir string
+}
+
+// Server watches Config.SourceDir, rebuilds on change, and proxies to the
+// rebuilt binary.
+type Server struct {
+	cfg Config
+
+	errorTmpl *template.Template
+	proxy     *httputil.ReverseProxy
+
+	mu       sync.RWMutex
+	buildErr *BuildError
+	cmd      *exec.Cmd
+
+	waitersMu sync.Mutex
+	waiters   []chan struct{}
+}
+
+// New creates a Server from cfg, parsing the embedded error-page template.
+func New(cfg Config) (*Server, error) {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 500 * time.Millisecond
+	}
+	if cfg.BuildDir == "" {
+		dir, err := os.MkdirTemp("", "zelang-serve-")
+		if err != nil {
+			return nil, fmt.Errorf("devserver: failed to create build dir: %w", err)
+		}
+		cfg.BuildDir = dir
+	}
+
+	tmpl, err := template.ParseFS(templatesFS, "templates/build_error.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("devserver: failed to parse error template: %w", err)
+	}
+
+	backendURL, err := url.Parse("http://" + cfg.BackendAddr)
+	if err != nil {
+		return nil, fmt.Errorf("devserver: invalid backend address %q: %w", cfg.BackendAddr, err)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(backendURL)
+	proxy.ModifyResponse = injectLivereload
+
+	return &Server{
+		cfg:       cfg,
+		errorTmpl: tmpl,
+		proxy:     proxy,
+	}, nil
+}
+
+// injectLivereload appends livereloadScript before </body> in HTML
+// responses, adjusting Content-Length to match.
+func injectLivereload(resp *http.Response) error {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if idx := bytes.LastIndex(body, []byte("</body>")); idx != -1 {
+		body = append(body[:idx], append([]byte(livereloadScript), body[idx:]...)...)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", fmt.Sprintf("%d", len(body)))
+	return nil
+}
+
+// errorTemplate renders ctx (a *BuildError) through build_error.tmpl.
+func (s *Server) errorTemplate(ctx interface{}) (io.Reader, error) {
+	var buf bytes.Buffer
+	if err := s.errorTmpl.Execute(&buf, ctx); err != nil {
+		return nil, fmt.Errorf("devserver: failed to render error template: %w", err)
+	}
+	return &buf, nil
+}
+
+// Run starts watching for source changes and serves the proxy until ctx is
+// canceled.
+func (s *Server) Run(ctx context.Context) error {
+	go s.watch(ctx)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/__livereload/wait", s.handleLivereloadWait)
+	mux.HandleFunc("/", s.handleProxy)
+
+	srv := &http.Server{Addr: s.cfg.Addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("devserver: listening on %s, proxying to %s", s.cfg.Addr, s.cfg.BackendAddr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// watch polls SourceDir for changes and triggers a rebuild whenever any
+// *.zl file's mtime advances, until ctx is canceled.
+func (s *Server) watch(ctx context.Context) {
+	mtimes := map[string]time.Time{}
+	s.rebuild()
+	s.notifyWaiters()
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := s.scanChanged(mtimes)
+			if err != nil {
+				log.Printf("devserver: scan error: %v", err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			s.rebuild()
+			if s.currentBuildErr() == nil {
+				s.notifyWaiters()
+			}
+		}
+	}
+}
+
+func (s *Server) scanChanged(mtimes map[string]time.Time) (bool, error) {
+	changed := false
+	err := filepath.WalkDir(s.cfg.SourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".zl" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if prev, ok := mtimes[path]; !ok || info.ModTime().After(prev) {
+			mtimes[path] = info.ModTime()
+			changed = true
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// rebuild parses every .zl file in SourceDir, generates C code, and
+// compiles it, recording the outcome in s.buildErr and restarting the
+// backend process on success.
+func (s *Server) rebuild() {
+	source, err := s.readSources()
+	if err != nil {
+		s.setBuildErr(&BuildError{Message: err.Error()})
+		return
+	}
+
+	l := lexer.New(source)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if errs := p.DetailedErrors(); len(errs) > 0 {
+		first := errs[0]
+		s.setBuildErr(&BuildError{
+			File:    s.cfg.SourceDir,
+			Line:    first.Line,
+			Column:  first.Column,
+			Message: first.Error(),
+			Excerpt: excerpt(source, first.Line),
+		})
+		return
+	}
+
+	gen, err := codegen.NewTemplateGenerator()
+	if err != nil {
+		s.setBuildErr(&BuildError{Message: fmt.Sprintf("failed to create generator: %v", err)})
+		return
+	}
+
+	cCode, err := gen.Generate(program)
+	if err != nil {
+		s.setBuildErr(&BuildError{Message: fmt.Sprintf("codegen failed: %v", err)})
+		return
+	}
+
+	binPath, err := s.compile(cCode)
+	if err != nil {
+		s.setBuildErr(&BuildError{Message: err.Error()})
+		return
+	}
+
+	s.setBuildErr(nil)
+	s.restart(binPath)
+}
+
+// readSources concatenates every *.zl file under SourceDir in sorted order.
+func (s *Server) readSources() (string, error) {
+	var paths []string
+	err := filepath.WalkDir(s.cfg.SourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".zl" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to scan %s: %w", s.cfg.SourceDir, err)
+	}
+	sort.Strings(paths)
+
+	var combined strings.Builder
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		combined.Write(contents)
+		combined.WriteString("\n")
+	}
+	return combined.String(), nil
+}
+
+// compile writes cCode to BuildDir and compiles it with $CC (default "cc").
+func (s *Server) compile(cCode string) (string, error) {
+	cPath := filepath.Join(s.cfg.BuildDir, "app.c")
+	if err := os.WriteFile(cPath, []byte(cCode), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", cPath, err)
+	}
+
+	cc := os.Getenv("CC")
+	if cc == "" {
+		cc = "cc"
+	}
+	binPath := filepath.Join(s.cfg.BuildDir, "app")
+
+	cmd := exec.Command(cc, cPath, "-o", binPath, "-lsqlite3", "-lmicrohttpd")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("compile failed: %s", stderr.String())
+	}
+
+	return binPath, nil
+}
+
+// restart stops the previously running backend, if any, and starts binPath.
+func (s *Server) restart(binPath string) {
+	s.mu.Lock()
+	prev := s.cmd
+	s.mu.Unlock()
+
+	if prev != nil && prev.Process != nil {
+		prev.Process.Kill()
+		prev.Wait()
+	}
+
+	cmd := exec.Command(binPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		s.setBuildErr(&BuildError{Message: fmt.Sprintf("failed to start %s: %v", binPath, err)})
+		return
+	}
+
+	s.mu.Lock()
+	s.cmd = cmd
+	s.mu.Unlock()
+}
+
+func (s *Server) setBuildErr(buildErr *BuildError) {
+	s.mu.Lock()
+	s.buildErr = buildErr
+	s.mu.Unlock()
+	if buildErr != nil {
+		log.Printf("devserver: build failed: %s", buildErr.Message)
+	}
+}
+
+func (s *Server) currentBuildErr() *BuildError {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.buildErr
+}
+
+// handleProxy serves the build-error page while the last build is broken,
+// otherwise forwards to the backend and injects the livereload script into
+// HTML responses.
+func (s *Server) handleProxy(w http.ResponseWriter, r *http.Request) {
+	if buildErr := s.currentBuildErr(); buildErr != nil {
+		body, err := s.errorTemplate(buildErr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, body)
+		return
+	}
+
+	s.proxy.ServeHTTP(w, r)
+}
+
+// handleLivereloadWait blocks until the next successful rebuild, or until
+// the client disconnects.
+func (s *Server) handleLivereloadWait(w http.ResponseWriter, r *http.Request) {
+	ch := make(chan struct{}, 1)
+	s.waitersMu.Lock()
+	s.waiters = append(s.waiters, ch)
+	s.waitersMu.Unlock()
+
+	select {
+	case <-ch:
+		w.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+	}
+}
+
+func (s *Server) notifyWaiters() {
+	s.waitersMu.Lock()
+	waiters := s.waiters
+	s.waiters = nil
+	s.waitersMu.Unlock()
+
+	for _, ch := range waiters {
+		ch <- struct{}{}
+	}
+}
+
+// excerpt returns the source line at (1-indexed) line, for display in the
+// build-error page.
+func excerpt(source string, line int) string {
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return lines[line-1]
+}
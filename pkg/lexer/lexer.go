@@ -2,6 +2,8 @@ package lexer
 
 import (
 	"unicode"
+
+	"github.com/gunesh/zelang/pkg/token"
 )
 
 type Lexer struct {
@@ -11,18 +13,57 @@ type Lexer struct {
 	ch           byte // current char under examination
 	line         int
 	column       int
+
+	fset *token.FileSet
+	file *token.File
+
+	scanComments bool
 }
 
+// New creates a Lexer over input with no associated filename. Use NewFile
+// when the source came from a named file and diagnostics should report it.
 func New(input string) *Lexer {
+	return NewFile("", input)
+}
+
+// NewFile creates a Lexer over input, registering it with a fresh FileSet
+// under filename so tokens' Pos/EndPos resolve to real file:line:col
+// positions via FileSet().Position(...).
+func NewFile(filename, input string) *Lexer {
+	return NewFileIn(token.NewFileSet(), filename, input)
+}
+
+// NewFileIn creates a Lexer over input like NewFile, but registers it with
+// an existing fset instead of a fresh one - for parsing multiple files (e.g.
+// parser.ParseDir) whose Pos values need to compare consistently across the
+// whole set.
+func NewFileIn(fset *token.FileSet, filename, input string) *Lexer {
 	l := &Lexer{
 		input:  input,
 		line:   1,
 		column: 0,
+		fset:   fset,
+		file:   fset.AddFile(filename, len(input)),
 	}
 	l.readChar()
 	return l
 }
 
+// FileSet returns the FileSet that this Lexer's tokens' Pos/EndPos values
+// resolve against.
+func (l *Lexer) FileSet() *token.FileSet {
+	return l.fset
+}
+
+// ScanComments controls whether NextToken emits COMMENT tokens for `//`
+// and `/* */` comments (enabled) or silently skips over them as
+// whitespace (disabled, the default) - used by parser.NewWithComments to
+// preserve comments for formatting/doc tooling without changing behavior
+// for callers that don't ask for them.
+func (l *Lexer) ScanComments(enabled bool) {
+	l.scanComments = enabled
+}
+
 func (l *Lexer) readChar() {
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 // ASCII code for "NUL"
@@ -36,6 +77,7 @@ func (l *Lexer) readChar() {
 	if l.ch == '\n' {
 		l.line++
 		l.column = 0
+		l.file.AddLine(l.readPosition)
 	}
 }
 
@@ -46,11 +88,20 @@ func (l *Lexer) peekChar() byte {
 	return l.input[l.readPosition]
 }
 
-func (l *Lexer) NextToken() Token {
-	var tok Token
-
+// NextToken scans and returns the next token. Pos/EndPos are captured via
+// the deferred closure below so every return path - including the early
+// returns inside readIdentifier/readNumber/readString's callers - picks up
+// the correct begin (before skipWhitespace's target char) and end (first
+// byte past the token) offsets, translated to FileSet Pos values.
+func (l *Lexer) NextToken() (tok Token) {
 	l.skipWhitespace()
 
+	begin := l.position
+	defer func() {
+		tok.Pos = l.file.Pos(begin)
+		tok.EndPos = l.file.Pos(l.position)
+	}()
+
 	tok.Line = l.line
 	tok.Column = l.column
 
@@ -72,23 +123,35 @@ func (l *Lexer) NextToken() Token {
 		tok.Type = PLUS
 		tok.Literal = string(l.ch)
 	case '-':
-		tok.Type = MINUS
-		tok.Literal = string(l.ch)
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok.Type = ARROW
+			tok.Literal = string(ch) + string(l.ch)
+		} else {
+			tok.Type = MINUS
+			tok.Literal = string(l.ch)
+		}
 	case '*':
 		tok.Type = ASTERISK
 		tok.Literal = string(l.ch)
 	case '/':
-		// Check for comments
-		if l.peekChar() == '/' {
-			l.skipLineComment()
-			return l.NextToken()
-		} else if l.peekChar() == '*' {
-			l.skipBlockComment()
-			return l.NextToken()
-		} else {
-			tok.Type = SLASH
-			tok.Literal = string(l.ch)
+		// When scanComments is off, skipWhitespace already consumed any
+		// comment before we got here, so a bare '/' is always division.
+		// When it's on, skipWhitespace left comments in place for us to
+		// scan into COMMENT tokens instead.
+		if l.scanComments && l.peekChar() == '/' {
+			tok.Type = COMMENT
+			tok.Literal = l.readLineComment()
+			return tok
 		}
+		if l.scanComments && l.peekChar() == '*' {
+			tok.Type = COMMENT
+			tok.Literal = l.readBlockComment()
+			return tok
+		}
+		tok.Type = SLASH
+		tok.Literal = string(l.ch)
 	case '<':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -146,7 +209,17 @@ func (l *Lexer) NextToken() Token {
 		tok.Type = SEMICOLON
 		tok.Literal = string(l.ch)
 	case ':':
-		tok.Type = COLON
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok.Type = DEFINE
+			tok.Literal = string(ch) + string(l.ch)
+		} else {
+			tok.Type = COLON
+			tok.Literal = string(l.ch)
+		}
+	case '.':
+		tok.Type = DOT
 		tok.Literal = string(l.ch)
 	case '(':
 		tok.Type = LPAREN
@@ -197,9 +270,29 @@ func (l *Lexer) NextToken() Token {
 	return tok
 }
 
+// skipWhitespace consumes whitespace and, unless scanComments is enabled,
+// comments too - looping so that e.g. a line comment followed by more
+// whitespace followed by a block comment is all skipped before NextToken
+// captures the next token's begin Pos. With scanComments enabled, it stops
+// at a comment's opening `/` so NextToken's '/' case can scan it into a
+// COMMENT token instead of discarding it.
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		l.readChar()
+	for {
+		for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+			l.readChar()
+		}
+		if l.scanComments {
+			return
+		}
+		if l.ch == '/' && l.peekChar() == '/' {
+			l.skipLineComment()
+			continue
+		}
+		if l.ch == '/' && l.peekChar() == '*' {
+			l.skipBlockComment()
+			continue
+		}
+		break
 	}
 }
 
@@ -233,6 +326,36 @@ func (l *Lexer) skipBlockComment() {
 	}
 }
 
+// readLineComment reads a `//` comment, including its marker, up to (not
+// including) the newline. Only called when scanComments is enabled.
+func (l *Lexer) readLineComment() string {
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+	return l.input[position:l.position]
+}
+
+// readBlockComment reads a `/* */` comment, including both markers. Only
+// called when scanComments is enabled.
+func (l *Lexer) readBlockComment() string {
+	position := l.position
+	l.readChar() // skip /
+	l.readChar() // skip *
+	for {
+		if l.ch == 0 {
+			break
+		}
+		if l.ch == '*' && l.peekChar() == '/' {
+			l.readChar() // skip *
+			l.readChar() // skip /
+			break
+		}
+		l.readChar()
+	}
+	return l.input[position:l.position]
+}
+
 func (l *Lexer) readIdentifier() string {
 	position := l.position
 	for isLetter(l.ch) || isDigit(l.ch) || l.ch == '_' {
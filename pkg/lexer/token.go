@@ -1,5 +1,7 @@
 package lexer
 
+import "github.com/gunesh/zelang/pkg/token"
+
 type TokenType string
 
 const (
@@ -8,10 +10,11 @@ const (
 	ILLEGAL TokenType = "ILLEGAL"
 
 	// Identifiers and literals
-	IDENT  TokenType = "IDENT"  // variable names, function names
-	INT    TokenType = "INT"    // 123
-	FLOAT  TokenType = "FLOAT"  // 123.45
-	STRING TokenType = "STRING" // "hello"
+	IDENT   TokenType = "IDENT"   // variable names, function names
+	INT     TokenType = "INT"    // 123
+	FLOAT   TokenType = "FLOAT"  // 123.45
+	STRING  TokenType = "STRING" // "hello"
+	COMMENT TokenType = "COMMENT" // // line or /* block */, only emitted when the Lexer's ScanComments is enabled
 
 	// Keywords
 	STRUCT   TokenType = "STRUCT"
@@ -26,6 +29,7 @@ const (
 	FOR      TokenType = "FOR"
 	WHILE    TokenType = "WHILE"
 	RETURN   TokenType = "RETURN"
+	BREAK    TokenType = "BREAK"
 	TRUE     TokenType = "TRUE"
 	FALSE    TokenType = "FALSE"
 	VOID     TokenType = "VOID"
@@ -40,6 +44,9 @@ const (
 	HANDLER  TokenType = "HANDLER"
 	REQUEST  TokenType = "REQUEST"
 	RESPONSE TokenType = "RESPONSE"
+	HOOK     TokenType = "HOOK"
+	ON       TokenType = "ON"
+	FUNCTION TokenType = "FUNCTION"
 
 	// Operators
 	ASSIGN   TokenType = "="
@@ -56,11 +63,14 @@ const (
 	AND      TokenType = "&&"
 	OR       TokenType = "||"
 	NOT      TokenType = "!"
+	ARROW    TokenType = "->"
+	DEFINE   TokenType = ":=" // inferred declaration, e.g. `x := 5`
 
 	// Delimiters
 	COMMA     TokenType = ","
 	SEMICOLON TokenType = ";"
 	COLON     TokenType = ":"
+	DOT       TokenType = "."
 	LPAREN    TokenType = "("
 	RPAREN    TokenType = ")"
 	LBRACE    TokenType = "{"
@@ -78,6 +88,12 @@ type Token struct {
 	Literal string
 	Line    int
 	Column  int
+
+	// Pos and EndPos are the begin (inclusive) and end (exclusive) source
+	// positions of this token, resolvable to a file:line:col via the
+	// *token.FileSet returned by the originating Lexer's FileSet method.
+	Pos    token.Pos
+	EndPos token.Pos
 }
 
 var keywords = map[string]TokenType{
@@ -93,6 +109,7 @@ var keywords = map[string]TokenType{
 	"for":      FOR,
 	"while":    WHILE,
 	"return":   RETURN,
+	"break":    BREAK,
 	"true":     TRUE,
 	"false":    FALSE,
 	"void":     VOID,
@@ -107,6 +124,9 @@ var keywords = map[string]TokenType{
 	"handler":  HANDLER,
 	"Request":  REQUEST,
 	"Response": RESPONSE,
+	"hook":     HOOK,
+	"on":       ON,
+	"function": FUNCTION,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/lexer"
+)
+
+// scanNext returns the next non-comment token from the lexer. When
+// scanComments is enabled, any run of COMMENT tokens it passes over is
+// grouped and classified: a group on the same source line as p.curToken
+// (the token immediately before the gap being scanned) becomes a pending
+// lineComment for whatever declaration p.curToken just ended; a group
+// immediately preceding - no blank line - the next real token becomes a
+// pending leadComment, the next declaration's doc comment. Either way it's
+// also appended to p.comments. Neither classification applies (a
+// "floating" comment) and the group is recorded but not attached.
+func (p *Parser) scanNext() lexer.Token {
+	tok := p.l.NextToken()
+	if p.mode&ParseComments == 0 {
+		return tok
+	}
+
+	for tok.Type == lexer.COMMENT {
+		startLine := p.fset.Position(tok.Pos).Line
+		prevLine := p.fset.Position(p.curToken.EndPos).Line
+
+		group, endLine, next := p.readCommentGroup(tok)
+		p.comments = append(p.comments, group)
+
+		switch {
+		case startLine == prevLine:
+			p.lineComment = group
+		case p.fset.Position(next.Pos).Line == endLine+1:
+			p.leadComment = group
+		}
+
+		tok = next
+	}
+	return tok
+}
+
+// readCommentGroup collects first and any immediately-following COMMENT
+// tokens (no non-comment token between them) into one CommentGroup, and
+// returns it along with the line its last comment ends on and the next
+// non-comment token.
+func (p *Parser) readCommentGroup(first lexer.Token) (group *ast.CommentGroup, endLine int, next lexer.Token) {
+	group = &ast.CommentGroup{}
+	tok := first
+	for tok.Type == lexer.COMMENT {
+		group.List = append(group.List, &ast.Comment{Text: tok.Literal, StartPos: tok.Pos, EndPos: tok.EndPos})
+		endLine = p.fset.Position(tok.EndPos).Line
+		tok = p.l.NextToken()
+	}
+	return group, endLine, tok
+}
+
+// takeDoc returns and clears the pending leadComment, for a declaration
+// parser to attach as its Doc field at entry.
+func (p *Parser) takeDoc() *ast.CommentGroup {
+	doc := p.leadComment
+	p.leadComment = nil
+	return doc
+}
+
+// takeLineComment returns and clears the pending lineComment, for a loop
+// to attach as the LineComment of the declaration it just finished parsing,
+// right after the p.nextToken() call that looks far enough ahead to see it.
+func (p *Parser) takeLineComment() *ast.CommentGroup {
+	lc := p.lineComment
+	p.lineComment = nil
+	return lc
+}
+
+// attachLineComment sets node's LineComment field to lc, for the handful
+// of declaration types that have one. A no-op for lc == nil or a node type
+// without a LineComment field.
+func attachLineComment(node ast.Node, lc *ast.CommentGroup) {
+	if lc == nil {
+		return
+	}
+	switch n := node.(type) {
+	case *ast.StructDecl:
+		n.LineComment = lc
+	case *ast.FieldDecl:
+		n.LineComment = lc
+	case *ast.PageDecl:
+		n.LineComment = lc
+	case *ast.HandlerDecl:
+		n.LineComment = lc
+	case *ast.FunctionDecl:
+		n.LineComment = lc
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gunesh/zelang/pkg/token"
+)
+
+// Error is a single parse failure tied to a resolved source position,
+// modeled on go/scanner.Error.
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+func (e Error) Error() string {
+	if e.Pos.Filename != "" || e.Pos.IsValid() {
+		return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+	}
+	return e.Msg
+}
+
+// ErrorList is a sortable list of parse errors, modeled on
+// go/scanner.ErrorList: Add appends in encounter order, Sort restores
+// position order (useful once errors have been collected from more than
+// one file or recovery point), and Err turns an empty list into a nil
+// error so callers can keep writing `if err := list.Err(); err != nil`.
+type ErrorList []*Error
+
+// Add appends an error at pos to the list.
+func (l *ErrorList) Add(pos token.Position, msg string) {
+	*l = append(*l, &Error{Pos: pos, Msg: msg})
+}
+
+func (l ErrorList) Len() int      { return len(l) }
+func (l ErrorList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+func (l ErrorList) Less(i, j int) bool {
+	a, b := l[i].Pos, l[j].Pos
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// Sort orders the list by source position.
+func (l ErrorList) Sort() {
+	sort.Sort(l)
+}
+
+// Error formats the first error, noting how many more follow.
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0].Error(), len(l)-1)
+}
+
+// Err returns nil if the list is empty, l otherwise.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Strings renders each error as "file:line:col: msg", matching the prior
+// []string shape that Parser.Errors() exposed.
+func (l ErrorList) Strings() []string {
+	out := make([]string, len(l))
+	for i, e := range l {
+		out[i] = e.Error()
+	}
+	return out
+}
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"strconv"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/lexer"
+)
+
+// Operator precedence, lowest to highest - a standard Pratt/precedence
+// climbing table keyed off lexer.TokenType, in the style of Monkey's
+// parser (this package's existing curToken/peekToken/nextToken shape
+// already follows that book closely).
+const (
+	_ int = iota
+	LOWEST
+	ASSIGNMENT  // =
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
+	EQUALS      // == !=
+	LESSGREATER // < > <= >=
+	SUM         // + -
+	PRODUCT     // * /
+	PREFIX      // -x  !x
+	CALL        // f(x)  arr[x]  obj.field
+)
+
+var precedences = map[lexer.TokenType]int{
+	lexer.ASSIGN:   ASSIGNMENT,
+	lexer.OR:       LOGICAL_OR,
+	lexer.AND:      LOGICAL_AND,
+	lexer.EQ:       EQUALS,
+	lexer.NOT_EQ:   EQUALS,
+	lexer.LT:       LESSGREATER,
+	lexer.GT:       LESSGREATER,
+	lexer.LTE:      LESSGREATER,
+	lexer.GTE:      LESSGREATER,
+	lexer.PLUS:     SUM,
+	lexer.MINUS:    SUM,
+	lexer.SLASH:    PRODUCT,
+	lexer.ASTERISK: PRODUCT,
+	lexer.LPAREN:   CALL,
+	lexer.LBRACKET: CALL,
+	lexer.DOT:      CALL,
+}
+
+func (p *Parser) peekPrecedence() int {
+	if pr, ok := precedences[p.peekToken.Type]; ok {
+		return pr
+	}
+	return LOWEST
+}
+
+func (p *Parser) curPrecedence() int {
+	if pr, ok := precedences[p.curToken.Type]; ok {
+		return pr
+	}
+	return LOWEST
+}
+
+type prefixParseFn func() ast.Expr
+type infixParseFn func(left ast.Expr) ast.Expr
+
+func (p *Parser) prefixParseFn() prefixParseFn {
+	switch p.curToken.Type {
+	case lexer.IDENT:
+		return p.parseIdent
+	case lexer.INT:
+		return p.parseIntLit
+	case lexer.FLOAT:
+		return p.parseFloatLit
+	case lexer.STRING:
+		return p.parseStringLit
+	case lexer.TRUE, lexer.FALSE:
+		return p.parseBoolLit
+	case lexer.NOT, lexer.MINUS:
+		return p.parseUnaryExpr
+	case lexer.LPAREN:
+		return p.parseGroupedExpr
+	default:
+		return nil
+	}
+}
+
+func (p *Parser) infixParseFn() infixParseFn {
+	switch p.curToken.Type {
+	case lexer.PLUS, lexer.MINUS, lexer.SLASH, lexer.ASTERISK,
+		lexer.EQ, lexer.NOT_EQ, lexer.LT, lexer.GT, lexer.LTE, lexer.GTE,
+		lexer.AND, lexer.OR, lexer.ASSIGN:
+		return p.parseBinaryExpr
+	case lexer.LPAREN:
+		return p.parseCallExpr
+	case lexer.LBRACKET:
+		return p.parseIndexExpr
+	case lexer.DOT:
+		return p.parseSelectorExpr
+	default:
+		return nil
+	}
+}
+
+// parseExpression is the Pratt parser's core loop: parse one prefix
+// expression, then keep folding in infix operators whose precedence beats
+// precedence, climbing the operator-precedence table as it goes.
+func (p *Parser) parseExpression(precedence int) ast.Expr {
+	prefix := p.prefixParseFn()
+	if prefix == nil {
+		p.addError(p.curToken.Pos, "no prefix parse function for %s found", p.curToken.Type)
+		return nil
+	}
+	left := prefix()
+
+	for !p.peekTokenIs(lexer.SEMICOLON) && precedence < p.peekPrecedence() {
+		p.nextToken()
+		fn := p.infixParseFn()
+		if fn == nil {
+			return left
+		}
+		left = fn(left)
+	}
+
+	return left
+}
+
+func (p *Parser) parseIdent() ast.Expr {
+	return &ast.Ident{Name: p.curToken.Literal, StartPos: p.curToken.Pos, EndPos: p.curToken.EndPos}
+}
+
+func (p *Parser) parseIntLit() ast.Expr {
+	v, err := strconv.ParseInt(p.curToken.Literal, 10, 64)
+	if err != nil {
+		p.addError(p.curToken.Pos, "could not parse %q as integer", p.curToken.Literal)
+		return nil
+	}
+	return &ast.IntLit{Value: v, StartPos: p.curToken.Pos, EndPos: p.curToken.EndPos}
+}
+
+func (p *Parser) parseFloatLit() ast.Expr {
+	v, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.addError(p.curToken.Pos, "could not parse %q as float", p.curToken.Literal)
+		return nil
+	}
+	return &ast.FloatLit{Value: v, StartPos: p.curToken.Pos, EndPos: p.curToken.EndPos}
+}
+
+func (p *Parser) parseStringLit() ast.Expr {
+	return &ast.StringLit{Value: p.curToken.Literal, StartPos: p.curToken.Pos, EndPos: p.curToken.EndPos}
+}
+
+func (p *Parser) parseBoolLit() ast.Expr {
+	return &ast.BoolLit{Value: p.curTokenIs(lexer.TRUE), StartPos: p.curToken.Pos, EndPos: p.curToken.EndPos}
+}
+
+func (p *Parser) parseUnaryExpr() ast.Expr {
+	startTok := p.curToken
+	op := p.curToken.Literal
+	p.nextToken()
+	operand := p.parseExpression(PREFIX)
+	if operand == nil {
+		return nil
+	}
+	return &ast.UnaryExpr{Op: op, Operand: operand, StartPos: startTok.Pos, EndPos: operand.End()}
+}
+
+func (p *Parser) parseGroupedExpr() ast.Expr {
+	p.nextToken() // skip (
+	expr := p.parseExpression(LOWEST)
+	if !p.expectPeek(lexer.RPAREN) {
+		return nil
+	}
+	return expr
+}
+
+func (p *Parser) parseBinaryExpr(left ast.Expr) ast.Expr {
+	op := p.curToken.Literal
+	precedence := p.curPrecedence()
+	p.nextToken()
+	right := p.parseExpression(precedence)
+	if right == nil {
+		return nil
+	}
+	return &ast.BinaryExpr{Left: left, Op: op, Right: right, StartPos: left.Pos(), EndPos: right.End()}
+}
+
+func (p *Parser) parseCallExpr(callee ast.Expr) ast.Expr {
+	call := &ast.CallExpr{Callee: callee, StartPos: callee.Pos()}
+	call.Args = p.parseExprList(lexer.RPAREN)
+	call.EndPos = p.curToken.EndPos // RPAREN
+	return call
+}
+
+func (p *Parser) parseIndexExpr(target ast.Expr) ast.Expr {
+	startPos := target.Pos()
+	p.nextToken() // skip [
+	index := p.parseExpression(LOWEST)
+	if !p.expectPeek(lexer.RBRACKET) {
+		return nil
+	}
+	return &ast.IndexExpr{Target: target, Index: index, StartPos: startPos, EndPos: p.curToken.EndPos}
+}
+
+func (p *Parser) parseSelectorExpr(target ast.Expr) ast.Expr {
+	startPos := target.Pos()
+	if !p.expectPeek(lexer.IDENT) {
+		return nil
+	}
+	return &ast.SelectorExpr{Target: target, Field: p.curToken.Literal, StartPos: startPos, EndPos: p.curToken.EndPos}
+}
+
+// parseExprList parses a comma-separated list of expressions up to (and
+// consuming) end, e.g. call arguments between `(` and `)`. Assumes curToken
+// is the opening delimiter on entry.
+func (p *Parser) parseExprList(end lexer.TokenType) []ast.Expr {
+	list := []ast.Expr{}
+
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return list
+	}
+
+	p.nextToken()
+	list = append(list, p.parseExpression(LOWEST))
+
+	for p.peekTokenIs(lexer.COMMA) {
+		p.nextToken() // move to ,
+		p.nextToken() // move to next expr
+		list = append(list, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return list
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/lexer"
+	"github.com/gunesh/zelang/pkg/token"
+)
+
+// ParseFile parses a single zelang source file, registering it with fset so
+// its nodes' Pos/EndPos resolve to real file:line:col positions. If src is
+// nil, filename is read from disk; otherwise src is parsed as if it were
+// the contents of filename, which need not exist.
+func ParseFile(fset *token.FileSet, filename string, src []byte, mode Mode) (*ast.Program, error) {
+	if src == nil {
+		var err error
+		src, err = os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l := lexer.NewFileIn(fset, filename, string(src))
+	p := NewMode(l, mode)
+	program := p.ParseProgram()
+	return program, p.ErrorList().Err()
+}
+
+// ParseDir parses every ".zl" file in path (filtered further by filter, when
+// given a non-nil func) into a map of Program keyed by file name, sharing a
+// single fset so positions across the whole directory compare consistently.
+func ParseDir(fset *token.FileSet, path string, filter func(fs.FileInfo) bool, mode Mode) (map[string]*ast.Program, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := make(map[string]*ast.Program)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".zl" {
+			continue
+		}
+		if filter != nil {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			if !filter(info) {
+				continue
+			}
+		}
+
+		filename := filepath.Join(path, entry.Name())
+		program, err := ParseFile(fset, filename, nil, mode)
+		if err != nil {
+			return nil, err
+		}
+		programs[entry.Name()] = program
+	}
+
+	return programs, nil
+}
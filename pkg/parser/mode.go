@@ -0,0 +1,37 @@
+package parser
+
+// Mode is a bitmask of optional parser behaviors, mirroring go/parser's
+// Mode. The zero Mode matches New's historical behavior: no comment
+// scanning, no tracing, every declaration's body parsed in full.
+type Mode uint
+
+const (
+	// ParseComments enables comment scanning and Doc/LineComment
+	// attachment, same as NewWithComments.
+	ParseComments Mode = 1 << iota
+
+	// Trace prints an indented entry line - current token, its literal,
+	// and its source position - and a matching exit line, to stdout, for
+	// each parseXxx call that trace is wired into (the declaration and
+	// statement parsers listed in trace.go; parseHookDecl and
+	// parseFieldDecl are not traced). Invaluable for debugging
+	// grammar regressions; very noisy on anything but a small snippet.
+	Trace
+
+	// DeclarationErrors reports an error (via Errors/ErrorList) for a
+	// top-level token that doesn't start any known declaration, instead
+	// of silently skipping it.
+	DeclarationErrors
+
+	// DeclsOnly stops recursing into function/handler bodies: Body is
+	// left nil for every FunctionDecl and HandlerDecl, and the tokens
+	// making up the body are skipped rather than parsed into statements.
+	// A fast pass for doc indexing or codegen planning that only needs
+	// signatures.
+	DeclsOnly
+
+	// SkipObjectResolution is reserved for parity with go/parser's Mode;
+	// this parser has no identifier/type resolution pass to skip yet, so
+	// the bit is currently a no-op.
+	SkipObjectResolution
+)
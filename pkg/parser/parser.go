@@ -2,22 +2,66 @@ package parser
 
 import (
 	"fmt"
+
 	"github.com/gunesh/zelang/pkg/ast"
 	"github.com/gunesh/zelang/pkg/lexer"
+	"github.com/gunesh/zelang/pkg/token"
 )
 
+// ParseError is a single parse failure with enough source position to
+// render an in-context diagnostic (e.g. in a dev-server error page).
+type ParseError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("Line %d:%d: %s", e.Line, e.Column, e.Message)
+}
+
 type Parser struct {
-	l      *lexer.Lexer
-	errors []string
+	l    *lexer.Lexer
+	fset *token.FileSet
+	mode Mode
+
+	errors         ErrorList
+	detailedErrors []ParseError
 
 	curToken  lexer.Token
 	peekToken lexer.Token
+
+	comments    []*ast.CommentGroup
+	leadComment *ast.CommentGroup
+	lineComment *ast.CommentGroup
+
+	traceDepth int
 }
 
 func New(l *lexer.Lexer) *Parser {
+	return NewMode(l, 0)
+}
+
+// NewWithComments creates a Parser that preserves comments: it switches l
+// into comment-scanning mode and attaches each CommentGroup it encounters
+// to the declaration it documents (Doc, for a group immediately preceding
+// a declaration on its own line) or trails (LineComment, for a group on
+// the same line as the end of a declaration), in addition to recording
+// every group in Program.Comments. Equivalent to NewMode(l, ParseComments).
+func NewWithComments(l *lexer.Lexer) *Parser {
+	return NewMode(l, ParseComments)
+}
+
+// NewMode creates a Parser over l with the given Mode bitmask - see Mode's
+// individual flags for what each bit enables.
+func NewMode(l *lexer.Lexer, mode Mode) *Parser {
+	if mode&ParseComments != 0 {
+		l.ScanComments(true)
+	}
 	p := &Parser{
-		l:      l,
-		errors: []string{},
+		l:    l,
+		fset: l.FileSet(),
+		mode: mode,
 	}
 
 	// Read two tokens so curToken and peekToken are both set
@@ -29,17 +73,39 @@ func New(l *lexer.Lexer) *Parser {
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+	p.peekToken = p.scanNext()
 }
 
+// Errors returns each parse error formatted as "file:line:col: msg".
 func (p *Parser) Errors() []string {
+	return p.errors.Strings()
+}
+
+// ErrorList returns the structured, sortable error list backing Errors()
+// and DetailedErrors().
+func (p *Parser) ErrorList() ErrorList {
 	return p.errors
 }
 
+// DetailedErrors returns the same errors as Errors, with source position
+// broken out for callers that need to point at the offending line/column
+// (e.g. a dev-server error page) instead of just logging a string.
+func (p *Parser) DetailedErrors() []ParseError {
+	return p.detailedErrors
+}
+
+// addError records a parse error at pos, keeping Errors(), ErrorList() and
+// DetailedErrors() in sync.
+func (p *Parser) addError(pos token.Pos, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	position := p.fset.Position(pos)
+	p.errors.Add(position, msg)
+	p.detailedErrors = append(p.detailedErrors, ParseError{Line: position.Line, Column: position.Column, Message: msg})
+}
+
 func (p *Parser) peekError(t lexer.TokenType) {
-	msg := fmt.Sprintf("Line %d:%d: expected next token to be %s, got %s instead",
-		p.peekToken.Line, p.peekToken.Column, t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken.Pos,
+		"expected next token to be %s, got %s instead", t, p.peekToken.Type)
 }
 
 func (p *Parser) curTokenIs(t lexer.TokenType) bool {
@@ -71,8 +137,12 @@ func (p *Parser) ParseProgram() *ast.Program {
 			program.Statements = append(program.Statements, stmt)
 		}
 		p.nextToken()
+		if stmt != nil {
+			attachLineComment(stmt, p.takeLineComment())
+		}
 	}
 
+	program.Comments = p.comments
 	return program
 }
 
@@ -88,7 +158,14 @@ func (p *Parser) parseStatement() ast.Node {
 		return p.parseHandlerDecl()
 	case lexer.INT_TYPE, lexer.FLOAT_TYPE, lexer.STRING_TYPE, lexer.BOOL_TYPE, lexer.VOID:
 		return p.parseFunctionDecl()
+	case lexer.HOOK:
+		return p.parseHookDecl()
+	case lexer.FUNCTION:
+		return p.parseNamedFunctionDecl()
 	default:
+		if p.mode&DeclarationErrors != 0 {
+			p.addError(p.curToken.Pos, "expected a declaration, got %s %q", p.curToken.Type, p.curToken.Literal)
+		}
 		return nil
 	}
 }
@@ -122,9 +199,12 @@ func (p *Parser) parseDecoratedStatement() ast.Node {
 }
 
 func (p *Parser) parseDecorators() []*ast.Decorator {
+	defer p.trace("parseDecorators")()
+
 	decorators := []*ast.Decorator{}
 
 	for p.curTokenIs(lexer.AT) {
+		atTok := p.curToken
 		p.nextToken() // skip @
 
 		if !p.curTokenIs(lexer.IDENT) {
@@ -132,9 +212,11 @@ func (p *Parser) parseDecorators() []*ast.Decorator {
 		}
 
 		decorator := &ast.Decorator{
-			Name:   p.curToken.Literal,
-			Args:   []string{},
-			KVArgs: make(map[string]string),
+			Name:     p.curToken.Literal,
+			Args:     []string{},
+			KVArgs:   make(map[string]string),
+			StartPos: atTok.Pos,
+			EndPos:   p.curToken.EndPos,
 		}
 
 		p.nextToken()
@@ -169,9 +251,10 @@ func (p *Parser) parseDecorators() []*ast.Decorator {
 
 			// We should now be at RPAREN
 			if !p.curTokenIs(lexer.RPAREN) {
-				p.errors = append(p.errors, fmt.Sprintf("Line %d:%d: expected ')' after decorator arguments", p.curToken.Line, p.curToken.Column))
+				p.addError(p.curToken.Pos, "expected ')' after decorator arguments")
 				return decorators
 			}
+			decorator.EndPos = p.curToken.EndPos
 			p.nextToken() // skip )
 		}
 
@@ -182,7 +265,9 @@ func (p *Parser) parseDecorators() []*ast.Decorator {
 }
 
 func (p *Parser) parseStructDecl() *ast.StructDecl {
-	structDecl := &ast.StructDecl{}
+	defer p.trace("parseStructDecl")()
+
+	structDecl := &ast.StructDecl{StartPos: p.curToken.Pos, Doc: p.takeDoc()}
 
 	if !p.expectPeek(lexer.IDENT) {
 		return nil
@@ -204,13 +289,17 @@ func (p *Parser) parseStructDecl() *ast.StructDecl {
 			structDecl.Fields = append(structDecl.Fields, field)
 		}
 		p.nextToken()
+		if field != nil {
+			field.LineComment = p.takeLineComment()
+		}
 	}
 
+	structDecl.EndPos = p.curToken.EndPos
 	return structDecl
 }
 
 func (p *Parser) parseFieldDecl() *ast.FieldDecl {
-	field := &ast.FieldDecl{}
+	field := &ast.FieldDecl{StartPos: p.curToken.Pos, Doc: p.takeDoc()}
 
 	// Check for decorators
 	if p.curTokenIs(lexer.AT) {
@@ -244,10 +333,11 @@ func (p *Parser) parseFieldDecl() *ast.FieldDecl {
 
 	// Expect semicolon
 	if !p.curTokenIs(lexer.SEMICOLON) {
-		p.errors = append(p.errors, fmt.Sprintf("Line %d:%d: expected ';' after field declaration", p.curToken.Line, p.curToken.Column))
+		p.addError(p.curToken.Pos, "expected ';' after field declaration")
 		return nil
 	}
 
+	field.EndPos = p.curToken.EndPos
 	return field
 }
 
@@ -258,9 +348,13 @@ func (p *Parser) isType(t lexer.TokenType) bool {
 }
 
 func (p *Parser) parsePageDecl() *ast.PageDecl {
+	defer p.trace("parsePageDecl")()
+
 	pageDecl := &ast.PageDecl{
 		Properties: make(map[string]string),
 		Body:       []ast.Node{},
+		StartPos:   p.curToken.Pos,
+		Doc:        p.takeDoc(),
 	}
 
 	if !p.expectPeek(lexer.IDENT) {
@@ -281,11 +375,14 @@ func (p *Parser) parsePageDecl() *ast.PageDecl {
 		p.nextToken()
 	}
 
+	pageDecl.EndPos = p.curToken.EndPos
 	return pageDecl
 }
 
 func (p *Parser) parseFunctionDecl() *ast.FunctionDecl {
-	funcDecl := &ast.FunctionDecl{}
+	defer p.trace("parseFunctionDecl")()
+
+	funcDecl := &ast.FunctionDecl{StartPos: p.curToken.Pos, Doc: p.takeDoc()}
 
 	// Parse return type
 	funcDecl.ReturnType = p.curToken.Literal
@@ -298,28 +395,134 @@ func (p *Parser) parseFunctionDecl() *ast.FunctionDecl {
 
 	funcDecl.Name = p.curToken.Literal
 
-	// For now, skip function body parsing
-	// Just skip to the end of the function
-	braceCount := 0
-	for !p.curTokenIs(lexer.EOF) {
-		if p.curTokenIs(lexer.LBRACE) {
-			braceCount++
-		} else if p.curTokenIs(lexer.RBRACE) {
-			braceCount--
-			if braceCount == 0 {
-				break
-			}
+	if !p.expectPeek(lexer.LBRACE) {
+		return nil
+	}
+	if p.mode&DeclsOnly != 0 {
+		p.skipBlock()
+	} else {
+		funcDecl.Body = p.parseBlockBody()
+	}
+
+	funcDecl.EndPos = p.curToken.EndPos
+	return funcDecl
+}
+
+// parseHookDecl parses `hook on <Struct> { event, event, ... }` (row events,
+// compiled to a sqlite3_update_hook dispatch) or `hook { event, event, ... }`
+// (on_commit/on_rollback, compiled to sqlite3_commit_hook/rollback_hook).
+// Events are bare identifiers; unlike FunctionDecl, a hook has no body to
+// parse.
+func (p *Parser) parseHookDecl() *ast.HookDecl {
+	hookDecl := &ast.HookDecl{Events: []string{}, StartPos: p.curToken.Pos}
+
+	p.nextToken() // skip 'hook'
+
+	if p.curTokenIs(lexer.ON) {
+		p.nextToken() // skip 'on'
+		if !p.curTokenIs(lexer.IDENT) {
+			return nil
 		}
+		hookDecl.StructName = p.curToken.Literal
 		p.nextToken()
 	}
 
+	if !p.curTokenIs(lexer.LBRACE) {
+		return nil
+	}
+	p.nextToken() // move into body
+
+	for !p.curTokenIs(lexer.RBRACE) && !p.curTokenIs(lexer.EOF) {
+		if p.curTokenIs(lexer.IDENT) {
+			hookDecl.Events = append(hookDecl.Events, p.curToken.Literal)
+		}
+		p.nextToken()
+		if p.curTokenIs(lexer.COMMA) {
+			p.nextToken()
+		}
+	}
+
+	hookDecl.EndPos = p.curToken.EndPos
+	return hookDecl
+}
+
+// parseNamedFunctionDecl parses `function <name>(<type> <arg>, ...) -> <type> { ... }`,
+// a user-defined scalar function callable from generated SQL via
+// sqlite3_create_function_v2.
+func (p *Parser) parseNamedFunctionDecl() *ast.FunctionDecl {
+	defer p.trace("parseNamedFunctionDecl")()
+
+	funcDecl := &ast.FunctionDecl{Parameters: []*ast.Parameter{}, StartPos: p.curToken.Pos, Doc: p.takeDoc()}
+
+	p.nextToken() // skip 'function'
+
+	if !p.curTokenIs(lexer.IDENT) {
+		return nil
+	}
+	funcDecl.Name = p.curToken.Literal
+
+	if !p.expectPeek(lexer.LPAREN) {
+		return nil
+	}
+	p.nextToken() // skip (
+
+	for !p.curTokenIs(lexer.RPAREN) && !p.curTokenIs(lexer.EOF) {
+		param := &ast.Parameter{StartPos: p.curToken.Pos}
+		if !p.isType(p.curToken.Type) {
+			return nil
+		}
+		param.Type = p.curToken.Literal
+		p.nextToken()
+
+		if p.curTokenIs(lexer.IDENT) {
+			param.Name = p.curToken.Literal
+			param.EndPos = p.curToken.EndPos
+			funcDecl.Parameters = append(funcDecl.Parameters, param)
+			p.nextToken()
+		}
+
+		if p.curTokenIs(lexer.COMMA) {
+			p.nextToken()
+		}
+	}
+
+	if !p.curTokenIs(lexer.RPAREN) {
+		return nil
+	}
+	p.nextToken() // skip )
+
+	if !p.curTokenIs(lexer.ARROW) {
+		return nil
+	}
+	p.nextToken() // skip ->
+
+	if !p.isType(p.curToken.Type) {
+		return nil
+	}
+	funcDecl.ReturnType = p.curToken.Literal
+	p.nextToken()
+
+	if !p.curTokenIs(lexer.LBRACE) {
+		return nil
+	}
+	if p.mode&DeclsOnly != 0 {
+		p.skipBlock()
+	} else {
+		funcDecl.Body = p.parseBlockBody()
+	}
+
+	funcDecl.EndPos = p.curToken.EndPos
 	return funcDecl
 }
 
 func (p *Parser) parseHandlerDecl() *ast.HandlerDecl {
+	defer p.trace("parseHandlerDecl")()
+
 	handlerDecl := &ast.HandlerDecl{
 		Parameters: []*ast.Parameter{},
 		Body:       []ast.Node{},
+		StartPos:   p.curToken.Pos,
+		Doc:        p.takeDoc(),
 	}
 
 	p.nextToken() // skip 'handler'
@@ -341,7 +544,7 @@ func (p *Parser) parseHandlerDecl() *ast.HandlerDecl {
 
 	// Parse parameter list
 	for !p.curTokenIs(lexer.RPAREN) && !p.curTokenIs(lexer.EOF) {
-		param := &ast.Parameter{}
+		param := &ast.Parameter{StartPos: p.curToken.Pos}
 
 		// Parse parameter type
 		param.Type = p.curToken.Literal
@@ -350,6 +553,7 @@ func (p *Parser) parseHandlerDecl() *ast.HandlerDecl {
 		// Parse parameter name
 		if p.curTokenIs(lexer.IDENT) {
 			param.Name = p.curToken.Literal
+			param.EndPos = p.curToken.EndPos
 			handlerDecl.Parameters = append(handlerDecl.Parameters, param)
 			p.nextToken()
 		}
@@ -363,22 +567,17 @@ func (p *Parser) parseHandlerDecl() *ast.HandlerDecl {
 		return nil
 	}
 
+	handlerDecl.EndPos = p.curToken.EndPos
 	p.nextToken() // skip )
 
-	// Skip to the end of function body
 	if p.curTokenIs(lexer.LBRACE) {
-		braceCount := 0
-		for !p.curTokenIs(lexer.EOF) {
-			if p.curTokenIs(lexer.LBRACE) {
-				braceCount++
-			} else if p.curTokenIs(lexer.RBRACE) {
-				braceCount--
-				if braceCount == 0 {
-					break
-				}
-			}
-			p.nextToken()
+		if p.mode&DeclsOnly != 0 {
+			p.skipBlock()
+			handlerDecl.Body = nil
+		} else {
+			handlerDecl.Body = p.parseBlockBody()
 		}
+		handlerDecl.EndPos = p.curToken.EndPos
 	}
 
 	return handlerDecl
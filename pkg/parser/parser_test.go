@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/lexer"
+)
+
+func TestDeclsOnlyModeSkipsFunctionBodies(t *testing.T) {
+	src := `
+function add(int a, int b) -> int {
+	return a + b;
+}
+`
+	p := NewMode(lexer.New(src), DeclsOnly)
+	program := p.ParseProgram()
+
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("unexpected parse errors: %v", errs)
+	}
+	if len(program.Statements) != 1 {
+		t.Fatalf("expected 1 top-level statement, got %d", len(program.Statements))
+	}
+
+	fn, ok := program.Statements[0].(*ast.FunctionDecl)
+	if !ok {
+		t.Fatalf("expected *ast.FunctionDecl, got %T", program.Statements[0])
+	}
+	if fn.Name != "add" {
+		t.Errorf("expected function name %q, got %q", "add", fn.Name)
+	}
+	if fn.Body != nil {
+		t.Errorf("expected Body to be nil in DeclsOnly mode, got %v", fn.Body)
+	}
+}
@@ -0,0 +1,340 @@
+package parser
+
+import (
+	"github.com/gunesh/zelang/pkg/ast"
+	"github.com/gunesh/zelang/pkg/lexer"
+)
+
+// isBuiltinType reports whether t is one of the scalar type keywords valid
+// at the start of a local variable declaration. Unlike isType (used for
+// struct fields and function parameters), this deliberately excludes IDENT
+// - supporting `CustomType x;` locals would need two-token-of-lookahead
+// disambiguation against a bare call/assignment statement, which this first
+// cut of statement parsing doesn't attempt.
+func (p *Parser) isBuiltinType(t lexer.TokenType) bool {
+	return t == lexer.INT_TYPE || t == lexer.FLOAT_TYPE || t == lexer.STRING_TYPE ||
+		t == lexer.BOOL_TYPE || t == lexer.DATE || t == lexer.DATETIME
+}
+
+// parseBlockBody parses the statements between a `{` and its matching `}`.
+// curToken must be the opening LBRACE on entry; on return curToken is the
+// closing RBRACE (or EOF, on a malformed program).
+func (p *Parser) parseBlockBody() []ast.Node {
+	defer p.trace("parseBlockBody")()
+
+	body := []ast.Node{}
+
+	p.nextToken() // move past '{'
+	for !p.curTokenIs(lexer.RBRACE) && !p.curTokenIs(lexer.EOF) {
+		stmt := p.parseBodyStatement()
+		if stmt != nil {
+			body = append(body, stmt)
+		}
+		p.nextToken()
+	}
+
+	return body
+}
+
+// skipBlock advances past a function/handler body without building any
+// statement nodes, for DeclsOnly mode. Same entry/exit contract as
+// parseBlockBody: curToken is the opening LBRACE on entry, the closing
+// RBRACE (or EOF, on an unterminated block) on return.
+func (p *Parser) skipBlock() {
+	depth := 0
+	for {
+		switch p.curToken.Type {
+		case lexer.LBRACE:
+			depth++
+		case lexer.RBRACE:
+			depth--
+			if depth == 0 {
+				return
+			}
+		case lexer.EOF:
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// parseBodyStatement parses one statement inside a function/handler/if/for/
+// while body.
+func (p *Parser) parseBodyStatement() ast.Node {
+	defer p.trace("parseBodyStatement")()
+
+	switch {
+	case p.isBuiltinType(p.curToken.Type) && p.peekTokenIs(lexer.IDENT):
+		return p.parseTypedDeclStmt()
+	case p.curTokenIs(lexer.IDENT) && p.peekTokenIs(lexer.DEFINE):
+		return p.parseInferredDeclStmt()
+	case p.curTokenIs(lexer.IF):
+		return p.parseIfStmt()
+	case p.curTokenIs(lexer.FOR):
+		return p.parseForStmt()
+	case p.curTokenIs(lexer.WHILE):
+		return p.parseWhileStmt()
+	case p.curTokenIs(lexer.RETURN):
+		return p.parseReturnStmt()
+	case p.curTokenIs(lexer.BREAK):
+		return p.parseBreakStmt()
+	case p.curTokenIs(lexer.SEMICOLON):
+		return &ast.EmptyStmt{StartPos: p.curToken.Pos, EndPos: p.curToken.EndPos}
+	default:
+		return p.parseSimpleStmt()
+	}
+}
+
+func (p *Parser) parseTypedDeclStmt() *ast.TypedDeclStmt {
+	defer p.trace("parseTypedDeclStmt")()
+
+	stmt := &ast.TypedDeclStmt{StartPos: p.curToken.Pos, Type: p.curToken.Literal}
+
+	p.nextToken() // move to name
+	stmt.Name = p.curToken.Literal
+
+	if p.peekTokenIs(lexer.ASSIGN) {
+		p.nextToken() // move to =
+		p.nextToken() // move to value
+		stmt.Value = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(lexer.SEMICOLON) {
+		return nil
+	}
+	stmt.EndPos = p.curToken.EndPos
+	return stmt
+}
+
+// parseInferredDeclClause parses `name := value` without consuming a
+// trailing semicolon, so it can also serve as a for-loop init clause.
+func (p *Parser) parseInferredDeclClause() *ast.InferredDeclStmt {
+	defer p.trace("parseInferredDeclClause")()
+
+	stmt := &ast.InferredDeclStmt{StartPos: p.curToken.Pos, Name: p.curToken.Literal}
+
+	p.nextToken() // move to :=
+	p.nextToken() // move to value
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value != nil {
+		stmt.EndPos = stmt.Value.End()
+	}
+	return stmt
+}
+
+func (p *Parser) parseInferredDeclStmt() *ast.InferredDeclStmt {
+	stmt := p.parseInferredDeclClause()
+	if stmt == nil || stmt.Value == nil {
+		return nil
+	}
+	if !p.expectPeek(lexer.SEMICOLON) {
+		return nil
+	}
+	stmt.EndPos = p.curToken.EndPos
+	return stmt
+}
+
+// parseAssignOrExpr parses a plain expression and, if it turns out to be
+// an assignment (`target = value` - ASSIGN is just another entry in the
+// precedence table, so the Pratt parser already built it as a BinaryExpr),
+// repackages it as an *ast.AssignStmt. Otherwise it returns the expression
+// as-is, leaving the caller to decide whether a bare expression is valid
+// here (a call is; most other expressions aren't).
+func (p *Parser) parseAssignOrExpr() ast.Node {
+	expr := p.parseExpression(LOWEST)
+	if expr == nil {
+		return nil
+	}
+	if bin, ok := expr.(*ast.BinaryExpr); ok && bin.Op == "=" {
+		return &ast.AssignStmt{Target: bin.Left, Value: bin.Right, StartPos: bin.StartPos, EndPos: bin.EndPos}
+	}
+	return expr
+}
+
+// parseSimpleStmt handles the statements that start with neither a keyword
+// nor a builtin type: assignments and bare function calls.
+func (p *Parser) parseSimpleStmt() ast.Node {
+	defer p.trace("parseSimpleStmt")()
+
+	startPos := p.curToken.Pos
+
+	node := p.parseAssignOrExpr()
+	if node == nil {
+		return nil
+	}
+
+	var result ast.Node
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		result = n
+	case *ast.CallExpr:
+		result = &ast.FuncCallStmt{Call: n, StartPos: startPos, EndPos: n.End()}
+	default:
+		p.addError(startPos, "expected a statement, got expression %q", node.TokenLiteral())
+		return nil
+	}
+
+	if !p.expectPeek(lexer.SEMICOLON) {
+		return nil
+	}
+	switch s := result.(type) {
+	case *ast.AssignStmt:
+		s.EndPos = p.curToken.EndPos
+	case *ast.FuncCallStmt:
+		s.EndPos = p.curToken.EndPos
+	}
+	return result
+}
+
+// parseIfStmt parses `if <cond> { ... }`, followed optionally by `else if
+// <cond> { ... }` (recursively, stored as the single element of Else) or a
+// plain trailing `else { ... }` (whose statements populate Else directly).
+func (p *Parser) parseIfStmt() *ast.IfStmt {
+	defer p.trace("parseIfStmt")()
+
+	stmt := &ast.IfStmt{StartPos: p.curToken.Pos}
+
+	p.nextToken() // skip 'if'
+	stmt.Cond = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(lexer.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseBlockBody()
+	stmt.EndPos = p.curToken.EndPos // RBRACE
+
+	if p.peekTokenIs(lexer.ELSE) {
+		p.nextToken() // move to 'else'
+		if p.peekTokenIs(lexer.IF) {
+			p.nextToken() // move to 'if'
+			elseIf := p.parseIfStmt()
+			if elseIf != nil {
+				stmt.Else = []ast.Node{elseIf}
+				stmt.EndPos = elseIf.EndPos
+			}
+		} else if p.expectPeek(lexer.LBRACE) {
+			stmt.Else = p.parseBlockBody()
+			stmt.EndPos = p.curToken.EndPos
+		}
+	}
+
+	return stmt
+}
+
+// parseForClause parses one for-loop clause: an inferred declaration
+// (`i := 0`), or a plain expression - which parseAssignOrExpr turns into an
+// AssignStmt when it's actually `target = value`.
+func (p *Parser) parseForClause() ast.Node {
+	if p.curTokenIs(lexer.IDENT) && p.peekTokenIs(lexer.DEFINE) {
+		return p.parseInferredDeclClause()
+	}
+	return p.parseAssignOrExpr()
+}
+
+// parseForStmt parses three forms: `for { ... }` (infinite), `for <cond> {
+// ... }` (Go-style condition-only loop), and the full C-style `for <init>;
+// <cond>; <post> { ... }`, each clause of the latter independently optional.
+func (p *Parser) parseForStmt() *ast.ForStmt {
+	defer p.trace("parseForStmt")()
+
+	stmt := &ast.ForStmt{StartPos: p.curToken.Pos}
+	p.nextToken() // skip 'for'
+
+	if p.curTokenIs(lexer.LBRACE) {
+		stmt.Body = p.parseBlockBody()
+		stmt.EndPos = p.curToken.EndPos
+		return stmt
+	}
+
+	if !p.curTokenIs(lexer.SEMICOLON) {
+		first := p.parseForClause()
+		if first == nil {
+			return nil
+		}
+
+		if p.peekTokenIs(lexer.LBRACE) {
+			cond, ok := first.(ast.Expr)
+			if !ok {
+				p.addError(p.curToken.Pos, "expected a condition expression before '{'")
+				return nil
+			}
+			stmt.Cond = cond
+			p.nextToken() // move to {
+			stmt.Body = p.parseBlockBody()
+			stmt.EndPos = p.curToken.EndPos
+			return stmt
+		}
+
+		stmt.Init = first
+		if !p.expectPeek(lexer.SEMICOLON) {
+			return nil
+		}
+	}
+
+	p.nextToken() // move past the init ';'
+	if !p.curTokenIs(lexer.SEMICOLON) {
+		stmt.Cond = p.parseExpression(LOWEST)
+		if !p.expectPeek(lexer.SEMICOLON) {
+			return nil
+		}
+	}
+
+	p.nextToken() // move past the cond ';'
+	if !p.curTokenIs(lexer.LBRACE) {
+		stmt.Post = p.parseForClause()
+		if !p.expectPeek(lexer.LBRACE) {
+			return nil
+		}
+	}
+
+	stmt.Body = p.parseBlockBody()
+	stmt.EndPos = p.curToken.EndPos
+	return stmt
+}
+
+func (p *Parser) parseWhileStmt() *ast.WhileStmt {
+	defer p.trace("parseWhileStmt")()
+
+	stmt := &ast.WhileStmt{StartPos: p.curToken.Pos}
+
+	p.nextToken() // skip 'while'
+	stmt.Cond = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(lexer.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseBlockBody()
+	stmt.EndPos = p.curToken.EndPos
+	return stmt
+}
+
+func (p *Parser) parseReturnStmt() *ast.ReturnStmt {
+	defer p.trace("parseReturnStmt")()
+
+	stmt := &ast.ReturnStmt{StartPos: p.curToken.Pos}
+
+	if p.peekTokenIs(lexer.SEMICOLON) {
+		p.nextToken()
+		stmt.EndPos = p.curToken.EndPos
+		return stmt
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	if !p.expectPeek(lexer.SEMICOLON) {
+		return nil
+	}
+	stmt.EndPos = p.curToken.EndPos
+	return stmt
+}
+
+func (p *Parser) parseBreakStmt() *ast.BreakStmt {
+	defer p.trace("parseBreakStmt")()
+
+	stmt := &ast.BreakStmt{StartPos: p.curToken.Pos}
+	if !p.expectPeek(lexer.SEMICOLON) {
+		return nil
+	}
+	stmt.EndPos = p.curToken.EndPos
+	return stmt
+}
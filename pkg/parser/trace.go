@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// trace prints an indented "parseXxx" entry line - the current token, its
+// literal, and its source position - when Trace mode is set, and returns a
+// closer for the caller to invoke via defer that prints the matching exit
+// line. `defer p.trace("parseStructDecl")()` at the top of a parseXxx
+// function brackets its whole body. When Trace isn't set, trace returns a
+// no-op closer so call sites don't need their own mode check.
+//
+// Tracing is wired into the declaration parsers (parseDecorators,
+// parseStructDecl, parsePageDecl, and the function/handler declarations)
+// and the statement-level parsers in statement.go, not every expression
+// leaf in expression.go - that would multiply trace output far more than
+// it'd help debug a grammar regression.
+func (p *Parser) trace(name string) func() {
+	if p.mode&Trace == 0 {
+		return func() {}
+	}
+	indent := strings.Repeat(". ", p.traceDepth)
+	pos := p.fset.Position(p.curToken.Pos)
+	fmt.Printf("%s%s (%s %q) %s\n", indent, name, p.curToken.Type, p.curToken.Literal, pos)
+	p.traceDepth++
+	return func() {
+		p.traceDepth--
+		fmt.Printf("%s%s)\n", strings.Repeat(". ", p.traceDepth), name)
+	}
+}
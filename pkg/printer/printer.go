@@ -0,0 +1,386 @@
+// Package printer re-emits a parsed zelang Program as canonical source:
+// decorator arguments in a stable (sorted) order regardless of how the
+// parser's map-backed Decorator.KVArgs happened to iterate, 4-space
+// indentation inside nested bodies, one blank line between top-level
+// declarations, and comments re-attached via the Doc/LineComment fields
+// the parser populates when comment scanning is enabled (see
+// parser.NewWithComments).
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+const indentUnit = "    "
+
+// Fprint renders program as canonical zelang source.
+func Fprint(program *ast.Program) string {
+	p := &printer{}
+	p.statements(program.Statements)
+	return p.buf.String()
+}
+
+type printer struct {
+	buf bytes.Buffer
+}
+
+func (p *printer) statements(stmts []ast.Node) {
+	for i, stmt := range stmts {
+		if i > 0 {
+			p.buf.WriteString("\n")
+		}
+		p.node(stmt, 0)
+	}
+}
+
+func (p *printer) writeIndent(depth int) {
+	p.buf.WriteString(strings.Repeat(indentUnit, depth))
+}
+
+func (p *printer) writeDoc(doc *ast.CommentGroup, depth int) {
+	if doc == nil {
+		return
+	}
+	for _, c := range doc.List {
+		p.writeIndent(depth)
+		p.buf.WriteString(c.Text)
+		p.buf.WriteString("\n")
+	}
+}
+
+// writeTrailing appends lc's text to the current line, for a comment that
+// trails a declaration or statement rather than leading it.
+func (p *printer) writeTrailing(lc *ast.CommentGroup) {
+	if lc == nil {
+		return
+	}
+	for _, c := range lc.List {
+		p.buf.WriteString("  ")
+		p.buf.WriteString(c.Text)
+	}
+}
+
+func (p *printer) writeDecorators(decs []*ast.Decorator, depth int) {
+	for _, d := range decs {
+		p.writeIndent(depth)
+		p.buf.WriteString(renderDecorator(d))
+		p.buf.WriteString("\n")
+	}
+}
+
+func renderDecorator(d *ast.Decorator) string {
+	if len(d.Args) == 0 && len(d.KVArgs) == 0 {
+		return "@" + d.Name
+	}
+	parts := append([]string{}, d.Args...)
+	keys := make([]string, 0, len(d.KVArgs))
+	for k := range d.KVArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %s", k, d.KVArgs[k]))
+	}
+	return fmt.Sprintf("@%s(%s)", d.Name, strings.Join(parts, ", "))
+}
+
+func stringProps(props map[string]string, depth int, p *printer) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("%s: %s;\n", k, props[k]))
+	}
+}
+
+func (p *printer) closeBrace(depth int, lc *ast.CommentGroup) {
+	p.writeIndent(depth)
+	p.buf.WriteString("}")
+	p.writeTrailing(lc)
+	p.buf.WriteString("\n")
+}
+
+func (p *printer) node(n ast.Node, depth int) {
+	switch v := n.(type) {
+	case *ast.StructDecl:
+		p.writeDoc(v.Doc, depth)
+		p.writeDecorators(v.Decorators, depth)
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("struct %s {\n", v.Name))
+		for _, f := range v.Fields {
+			p.node(f, depth+1)
+		}
+		p.closeBrace(depth, v.LineComment)
+
+	case *ast.FieldDecl:
+		p.writeDoc(v.Doc, depth)
+		p.writeDecorators(v.Decorators, depth)
+		p.writeIndent(depth)
+		typ := v.Type
+		if v.IsArray {
+			typ += "[]"
+		}
+		p.buf.WriteString(fmt.Sprintf("%s %s;", typ, v.Name))
+		p.writeTrailing(v.LineComment)
+		p.buf.WriteString("\n")
+
+	case *ast.PageDecl:
+		p.writeDoc(v.Doc, depth)
+		p.writeDecorators(v.Decorators, depth)
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("Page %s {\n", v.Name))
+		stringProps(v.Properties, depth+1, p)
+		for _, b := range v.Body {
+			p.node(b, depth+1)
+		}
+		p.closeBrace(depth, v.LineComment)
+
+	case *ast.SectionDecl:
+		p.blockNode("Section", v.Properties, v.Body, depth)
+	case *ast.RowDecl:
+		p.blockNode("Row", v.Properties, v.Body, depth)
+	case *ast.ColumnDecl:
+		p.blockNode("Column", v.Properties, v.Body, depth)
+	case *ast.FormDecl:
+		p.blockNode("Form", v.Properties, v.Body, depth)
+	case *ast.InputDecl:
+		p.propsOnlyNode("Input", v.Properties, depth)
+	case *ast.ButtonDecl:
+		p.propsOnlyNode("Button", v.Properties, depth)
+
+	case *ast.DataListDecl:
+		p.writeIndent(depth)
+		p.buf.WriteString("DataList {\n")
+		keys := make([]string, 0, len(v.Properties))
+		for k := range v.Properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p.writeIndent(depth + 1)
+			p.buf.WriteString(fmt.Sprintf("%s: %v;\n", k, v.Properties[k]))
+		}
+		p.closeBrace(depth, nil)
+
+	case *ast.HandlerDecl:
+		p.writeDoc(v.Doc, depth)
+		p.writeDecorators(v.Decorators, depth)
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("handler %s(%s) {\n", v.Name, renderParams(v.Parameters)))
+		for _, s := range v.Body {
+			p.node(s, depth+1)
+		}
+		p.closeBrace(depth, v.LineComment)
+
+	case *ast.FunctionDecl:
+		p.writeDoc(v.Doc, depth)
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("function %s(%s) -> %s {\n", v.Name, renderParams(v.Parameters), v.ReturnType))
+		for _, s := range v.Body {
+			p.node(s, depth+1)
+		}
+		p.closeBrace(depth, v.LineComment)
+
+	case *ast.MainDecl:
+		p.writeIndent(depth)
+		p.buf.WriteString("main {\n")
+		for _, s := range v.Body {
+			p.node(s, depth+1)
+		}
+		p.closeBrace(depth, nil)
+
+	case *ast.HookDecl:
+		p.writeIndent(depth)
+		if v.StructName != "" {
+			p.buf.WriteString(fmt.Sprintf("hook on %s { %s }\n", v.StructName, strings.Join(v.Events, ", ")))
+		} else {
+			p.buf.WriteString(fmt.Sprintf("hook { %s }\n", strings.Join(v.Events, ", ")))
+		}
+
+	case *ast.IfStmt:
+		p.writeIndent(depth)
+		p.ifStmt(v, depth)
+	case *ast.ForStmt:
+		p.forStmt(v, depth)
+	case *ast.WhileStmt:
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("while (%s) {\n", renderExpr(v.Cond)))
+		for _, s := range v.Body {
+			p.node(s, depth+1)
+		}
+		p.closeBrace(depth, nil)
+	case *ast.ReturnStmt:
+		p.writeIndent(depth)
+		if v.Value == nil {
+			p.buf.WriteString("return;\n")
+		} else {
+			p.buf.WriteString(fmt.Sprintf("return %s;\n", renderExpr(v.Value)))
+		}
+	case *ast.BreakStmt:
+		p.writeIndent(depth)
+		p.buf.WriteString("break;\n")
+	case *ast.TypedDeclStmt:
+		p.writeIndent(depth)
+		if v.Value != nil {
+			p.buf.WriteString(fmt.Sprintf("%s %s = %s;\n", v.Type, v.Name, renderExpr(v.Value)))
+		} else {
+			p.buf.WriteString(fmt.Sprintf("%s %s;\n", v.Type, v.Name))
+		}
+	case *ast.InferredDeclStmt:
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("%s := %s;\n", v.Name, renderExpr(v.Value)))
+	case *ast.AssignStmt:
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("%s = %s;\n", renderExpr(v.Target), renderExpr(v.Value)))
+	case *ast.FuncCallStmt:
+		p.writeIndent(depth)
+		p.buf.WriteString(renderExpr(v.Call) + ";\n")
+	case *ast.EmptyStmt:
+		p.writeIndent(depth)
+		p.buf.WriteString(";\n")
+
+	default:
+		p.writeIndent(depth)
+		p.buf.WriteString(fmt.Sprintf("/* unsupported node %T */\n", n))
+	}
+}
+
+func (p *printer) blockNode(keyword string, props map[string]string, body []ast.Node, depth int) {
+	p.writeIndent(depth)
+	p.buf.WriteString(keyword + " {\n")
+	stringProps(props, depth+1, p)
+	for _, b := range body {
+		p.node(b, depth+1)
+	}
+	p.closeBrace(depth, nil)
+}
+
+func (p *printer) propsOnlyNode(keyword string, props map[string]string, depth int) {
+	p.writeIndent(depth)
+	p.buf.WriteString(keyword + " {\n")
+	stringProps(props, depth+1, p)
+	p.closeBrace(depth, nil)
+}
+
+func renderParams(params []*ast.Parameter) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = fmt.Sprintf("%s %s", param.Type, param.Name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ifStmt writes an if/else-if/else chain. The caller is responsible for the
+// leading indent of the very first "if"; every recursive "else if" and the
+// closing braces handle their own.
+func (p *printer) ifStmt(s *ast.IfStmt, depth int) {
+	p.buf.WriteString(fmt.Sprintf("if (%s) {\n", renderExpr(s.Cond)))
+	for _, b := range s.Body {
+		p.node(b, depth+1)
+	}
+	p.writeIndent(depth)
+	p.buf.WriteString("}")
+
+	if len(s.Else) == 1 {
+		if elseIf, ok := s.Else[0].(*ast.IfStmt); ok {
+			p.buf.WriteString(" else ")
+			p.ifStmt(elseIf, depth)
+			return
+		}
+	}
+	if len(s.Else) > 0 {
+		p.buf.WriteString(" else {\n")
+		for _, b := range s.Else {
+			p.node(b, depth+1)
+		}
+		p.writeIndent(depth)
+		p.buf.WriteString("}\n")
+		return
+	}
+	p.buf.WriteString("\n")
+}
+
+func (p *printer) forStmt(s *ast.ForStmt, depth int) {
+	p.writeIndent(depth)
+	cond := ""
+	if s.Cond != nil {
+		cond = renderExpr(s.Cond)
+	}
+	switch {
+	case s.Init == nil && s.Post == nil:
+		if s.Cond == nil {
+			p.buf.WriteString("for {\n")
+		} else {
+			p.buf.WriteString(fmt.Sprintf("for %s {\n", cond))
+		}
+	default:
+		p.buf.WriteString(fmt.Sprintf("for %s; %s; %s {\n", forClause(s.Init), cond, forClause(s.Post)))
+	}
+	for _, b := range s.Body {
+		p.node(b, depth+1)
+	}
+	p.closeBrace(depth, nil)
+}
+
+func forClause(n ast.Node) string {
+	switch v := n.(type) {
+	case nil:
+		return ""
+	case *ast.InferredDeclStmt:
+		return fmt.Sprintf("%s := %s", v.Name, renderExpr(v.Value))
+	case *ast.TypedDeclStmt:
+		if v.Value != nil {
+			return fmt.Sprintf("%s %s = %s", v.Type, v.Name, renderExpr(v.Value))
+		}
+		return fmt.Sprintf("%s %s", v.Type, v.Name)
+	case *ast.AssignStmt:
+		return fmt.Sprintf("%s = %s", renderExpr(v.Target), renderExpr(v.Value))
+	case ast.Expr:
+		return renderExpr(v)
+	default:
+		return fmt.Sprintf("/* unsupported for-clause %T */", n)
+	}
+}
+
+func renderExpr(e ast.Expr) string {
+	switch v := e.(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.IntLit:
+		return strconv.FormatInt(v.Value, 10)
+	case *ast.FloatLit:
+		return strconv.FormatFloat(v.Value, 'g', -1, 64)
+	case *ast.StringLit:
+		return strconv.Quote(v.Value)
+	case *ast.BoolLit:
+		if v.Value {
+			return "true"
+		}
+		return "false"
+	case *ast.UnaryExpr:
+		return v.Op + renderExpr(v.Operand)
+	case *ast.BinaryExpr:
+		return fmt.Sprintf("(%s %s %s)", renderExpr(v.Left), v.Op, renderExpr(v.Right))
+	case *ast.CallExpr:
+		args := make([]string, len(v.Args))
+		for i, a := range v.Args {
+			args[i] = renderExpr(a)
+		}
+		return fmt.Sprintf("%s(%s)", renderExpr(v.Callee), strings.Join(args, ", "))
+	case *ast.IndexExpr:
+		return fmt.Sprintf("%s[%s]", renderExpr(v.Target), renderExpr(v.Index))
+	case *ast.SelectorExpr:
+		return fmt.Sprintf("%s.%s", renderExpr(v.Target), v.Field)
+	default:
+		return fmt.Sprintf("/* unsupported expr %T */", e)
+	}
+}
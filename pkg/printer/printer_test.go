@@ -0,0 +1,94 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gunesh/zelang/pkg/ast"
+)
+
+func TestFprintStructDecl(t *testing.T) {
+	program := &ast.Program{
+		Statements: []ast.Node{
+			&ast.StructDecl{
+				Name: "Todo",
+				Doc:  &ast.CommentGroup{List: []*ast.Comment{{Text: "// a todo item"}}},
+				Decorators: []*ast.Decorator{
+					// KVArgs is a map; zoo/apple/bar exercises the sorted-key
+					// rendering rather than relying on map iteration order.
+					{Name: "table", KVArgs: map[string]string{"zoo": "1", "apple": "2", "bar": "3"}},
+				},
+				Fields: []*ast.FieldDecl{
+					{Name: "id", Type: "int"},
+					{Name: "title", Type: "string", LineComment: &ast.CommentGroup{List: []*ast.Comment{{Text: "// required"}}}},
+				},
+			},
+			&ast.PageDecl{Name: "TodoApp"},
+		},
+	}
+
+	out := Fprint(program)
+
+	expectedPatterns := []string{
+		"// a todo item",
+		"@table(apple: 2, bar: 3, zoo: 1)",
+		"struct Todo {",
+		"    int id;",
+		`    string title;  // required`,
+		"Page TodoApp {",
+	}
+	for _, pattern := range expectedPatterns {
+		if !strings.Contains(out, pattern) {
+			t.Errorf("Fprint output missing expected pattern: %s\ngot:\n%s", pattern, out)
+		}
+	}
+
+	// Exactly one blank line should separate the two top-level declarations.
+	if !strings.Contains(out, "}\n\nPage TodoApp") {
+		t.Errorf("expected a single blank line between top-level decls, got:\n%s", out)
+	}
+}
+
+func TestFprintHandlerBody(t *testing.T) {
+	program := &ast.Program{
+		Statements: []ast.Node{
+			&ast.HandlerDecl{
+				Name:   "Greet",
+				Path:   "/greet",
+				Method: "GET",
+				Parameters: []*ast.Parameter{
+					{Name: "name", Type: "string"},
+				},
+				Body: []ast.Node{
+					&ast.IfStmt{
+						Cond: &ast.BinaryExpr{
+							Left:  &ast.Ident{Name: "name"},
+							Op:    "==",
+							Right: &ast.StringLit{Value: ""},
+						},
+						Body: []ast.Node{
+							&ast.ReturnStmt{Value: &ast.IntLit{Value: 0}},
+						},
+					},
+					&ast.ReturnStmt{Value: &ast.IntLit{Value: 1}},
+				},
+			},
+		},
+	}
+
+	out := Fprint(program)
+
+	expectedPatterns := []string{
+		"handler Greet(string name) {",
+		"if (",
+		`name == ""`,
+		"        return 0;",
+		"    }",
+		"    return 1;",
+	}
+	for _, pattern := range expectedPatterns {
+		if !strings.Contains(out, pattern) {
+			t.Errorf("Fprint output missing expected pattern: %s\ngot:\n%s", pattern, out)
+		}
+	}
+}
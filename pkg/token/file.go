@@ -0,0 +1,69 @@
+package token
+
+import "sort"
+
+// File tracks the line boundaries of a single source file that has been
+// added to a FileSet, so a flat byte offset into that file can be resolved
+// back into a 1-based line and column.
+type File struct {
+	name  string
+	base  int // Pos of byte 0 of this file, assigned by the owning FileSet
+	size  int // length of the file's content in bytes
+	lines []int // byte offset of the start of each line; lines[0] == 0
+}
+
+// Name returns the file name as registered with the FileSet.
+func (f *File) Name() string { return f.name }
+
+// Base returns the Pos corresponding to byte offset 0 in this file.
+func (f *File) Base() int { return f.base }
+
+// Size returns the file's content length in bytes.
+func (f *File) Size() int { return f.size }
+
+// AddLine records that a new line begins at the given byte offset. Offsets
+// must be added in increasing order - the lexer calls this each time it
+// consumes a '\n', passing the offset of the character right after it.
+func (f *File) AddLine(offset int) {
+	if n := len(f.lines); (n == 0 || f.lines[n-1] < offset) && offset < f.size {
+		f.lines = append(f.lines, offset)
+	}
+}
+
+// Pos turns a byte offset within this file into a FileSet-wide Pos.
+func (f *File) Pos(offset int) Pos {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > f.size {
+		offset = f.size
+	}
+	return Pos(f.base + offset)
+}
+
+// Offset turns a FileSet-wide Pos belonging to this file back into a byte
+// offset.
+func (f *File) Offset(p Pos) int {
+	offset := int(p) - f.base
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > f.size {
+		offset = f.size
+	}
+	return offset
+}
+
+// position resolves a byte offset within this file into a Position.
+func (f *File) position(offset int) Position {
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     i + 1,
+		Column:   offset - f.lines[i] + 1,
+	}
+}
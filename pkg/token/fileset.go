@@ -0,0 +1,50 @@
+package token
+
+// FileSet interns the set of source files a Pos may refer to. Each file is
+// assigned a disjoint range of the Pos integer space (its "base"), so a bare
+// Pos is enough to find both the file it belongs to and the offset within
+// it, without the caller threading a *File around separately.
+type FileSet struct {
+	base  int // base offset for the next AddFile call; 0 is reserved for NoPos
+	files []*File
+}
+
+// NewFileSet creates a new, empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// AddFile registers a new source file of the given size (in bytes) and
+// returns the *File used to record its line boundaries and mint Pos values
+// for it. Offsets for the file's content must subsequently be turned into
+// Pos values via the returned File's Pos method.
+func (s *FileSet) AddFile(filename string, size int) *File {
+	f := &File{name: filename, base: s.base, size: size, lines: []int{0}}
+	s.base += size + 1 // +1 so File.Pos(size) (one-past-the-end) stays inside this file's range
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file containing p, or nil if p does not belong to any
+// file added to s.
+func (s *FileSet) File(p Pos) *File {
+	if p == NoPos {
+		return nil
+	}
+	for _, f := range s.files {
+		if int(p) >= f.base && int(p) <= f.base+f.size {
+			return f
+		}
+	}
+	return nil
+}
+
+// Position resolves p into a full Position, or the zero Position if p is
+// NoPos or unknown to s.
+func (s *FileSet) Position(p Pos) Position {
+	f := s.File(p)
+	if f == nil {
+		return Position{}
+	}
+	return f.position(int(p) - f.base)
+}
@@ -0,0 +1,54 @@
+// Package token provides a compact source-position representation modeled
+// on the standard library's go/token: a FileSet interns one or more source
+// files and hands out small integer Pos values that any package (lexer,
+// parser, ast, printer) can pass around and compare cheaply, resolving them
+// back to a human-readable file:line:col only when a diagnostic actually
+// needs to be printed.
+package token
+
+import "fmt"
+
+// Pos is an integer handle to a source position. It is only meaningful in
+// relation to the FileSet that produced it - compare/resolve Pos values
+// from the same FileSet. The zero value, NoPos, means "no position".
+type Pos int
+
+// NoPos is the zero Pos. (*FileSet).Position returns the zero Position for it.
+const NoPos Pos = 0
+
+// IsValid reports whether the position is known.
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// Position describes a resolved source location: a filename plus a byte
+// offset, line and column (both 1-based). It is the fully-expanded form of
+// a Pos, produced by FileSet.Position.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// IsValid reports whether the position is valid, i.e. has a known line.
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String formats the position as "file:line:col", omitting the filename if
+// it is empty. An invalid position formats as just the filename, or as "-"
+// if the filename is empty too.
+func (pos Position) String() string {
+	s := pos.Filename
+	if pos.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}